@@ -0,0 +1,121 @@
+package main
+
+import "math"
+
+// Метод AGA8-92DC (DETAIL characterization, ISO 12213-2). ГОСТ 30319.3 построен на том же уравнении
+// состояния: 58 членов, коэффициенты an, bn, cn, kn, un и параметры компонентов и пар одни и те же,
+// поэтому расчет использует общие функции getKx, getDU и getSigma. Отличия от ГОСТ 30319.3:
+//   - универсальная газовая постоянная 8,31451 Дж/(моль*К) вместо 8,314, она входит в давление
+//     нормировки и начальное приближение плотности;
+//   - доли компонентов берутся в том виде, в котором заданы, и нормируются на единицу. ГОСТ 30319.3
+//     переносит гелий и водород с долей до 0,0005 в азот (initFractions), AGA8 считает их
+//     отдельными компонентами со своими параметрами;
+//   - сумма долей, отличная от единицы в пределах допуска checkFullComposition, нормируется, а не
+//     остается как есть;
+//   - рассчитываются только коэффициент сжимаемости и плотность, калорические свойства и вязкость
+//     остаются за ГОСТ 30319.3;
+//   - молярные массы компонентов берутся из таблиц ГОСТ 30319.3, поэтому плотность сравнивается
+//     с контрольными значениями AGA8 только через коэффициент сжимаемости.
+
+// универсальная газовая постоянная AGA8-92DC, Дж/(моль*К)
+const aga8R = 8.31451
+
+// состав для расчета по AGA8: исходные доли, нормированные на единицу
+func newAGA8Context(ctx *context) *context {
+	fractions := ctx.inputFractions
+	if fractions == nil {
+		fractions = ctx.fractions
+	}
+	aga8 := *ctx
+	aga8.fractions = make([]componentFraction, len(fractions))
+	total := 0.0
+	for _, cf := range fractions {
+		total += cf.fraction
+	}
+	for i, cf := range fractions {
+		aga8.fractions[i] = componentFraction{cf.component, cf.fraction / total}
+	}
+	return &aga8
+}
+
+// результат расчета по AGA8
+type aga8Result struct {
+	kx    float64
+	mm    float64
+	iters []sigmaIteration
+	// коэффициент сжимаемости
+	z float64
+	// плотность, кг/м^3
+	density float64
+}
+
+// параметры AGA8, зависящие только от состава
+type aga8Mixture struct {
+	// нормированный состав
+	ctx *context
+	kx  float64
+	mm  float64
+	p0m float64
+	d   []float64
+	u   []float64
+}
+
+func newAGA8Mixture(ctx *context) *aga8Mixture {
+	m := &aga8Mixture{ctx: newAGA8Context(ctx)}
+	m.kx = getKx(m.ctx)
+	m.mm = getMm(m.ctx)
+	m.d, m.u = getDU(m.ctx, m.kx)
+	m.p0m = math.Pow10(-3) * math.Pow(m.kx, -3) * aga8R * Lt
+	return m
+}
+
+// расчет при давлении и температуре из контекста
+func (m *aga8Mixture) properties(ctx *context) (*aga8Result, error) {
+	point := *m.ctx
+	point.p, point.t = ctx.p, ctx.t
+	r := &aga8Result{kx: m.kx, mm: m.mm}
+	initialSigma := math.Pow10(3) * point.p * math.Pow(m.kx, 3) / (aga8R * point.t)
+	tau := getTau(&point)
+	var err error
+	r.iters, err = getSigma(&point, m.kx, getPi(&point, m.p0m), tau, initialSigma, m.d, m.u)
+	if err != nil {
+		return nil, err
+	}
+	sigma := r.iters[len(r.iters)-1].sigma
+	r.z = getZ(&point, sigma, tau, m.d, m.u)
+	r.density = getP(&point, m.kx, m.mm, sigma)
+	return r, nil
+}
+
+func getAGA8(ctx *context) (*aga8Result, error) {
+	return newAGA8Mixture(ctx).properties(ctx)
+}
+
+// расчет свойств по AGA8 с отклонением от ГОСТ 30319.3
+func calcAGA8(ctx *context, out *output) error {
+	r, err := getAGA8(ctx)
+	if err != nil {
+		return err
+	}
+	if out.level >= trace {
+		out.writeFractions(newAGA8Context(ctx))
+	}
+	if out.level >= normal {
+		out.writeKx(r.kx)
+		out.writeMm(r.mm)
+		out.writeSigmaIterations(r.iters)
+	}
+	out.writeP(r.density)
+	out.writeZ(r.z)
+	if out.level >= normal {
+		gp, err := getProperties(ctx, newMixture(ctx))
+		if err != nil {
+			return err
+		}
+		out.writeComparison([]comparisonRow{
+			{model: "gost3", z: gp.z, density: gp.density},
+			{model: "aga8", z: r.z, density: r.density},
+		})
+	}
+	return nil
+}
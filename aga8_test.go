@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAGA8AgainstGost303193(t *testing.T) {
+	ctx := n1Context()
+	for _, tc := range n1TestCases {
+		ctx.p, ctx.t = tc.p, tc.t
+		density, z := getPZ(ctx)
+		r := mustAGA8(ctx)
+		// уравнения совпадают, разница только в газовой постоянной
+		if math.Abs(r.z-z)/z > 1e-4 {
+			t.Errorf("AGA8 z deviates at p = %f, t = %f; aga8 = %f, gost3 = %f", tc.p, tc.t, r.z, z)
+		}
+		if math.Abs(r.density-density)/density > 2e-4 {
+			t.Errorf("AGA8 density deviates at p = %f, t = %f; aga8 = %f, gost3 = %f", tc.p, tc.t, r.density, density)
+		}
+	}
+}
+
+func TestAGA8KeepsInputFractions(t *testing.T) {
+	ctx := &context{fractions: []componentFraction{{&methane, 0.9996}, {&helium, 0.0004}}}
+	ctx.initFractions()
+	aga8 := newAGA8Context(ctx)
+	if aga8.component(1) != &helium || aga8.fraction(1) != 0.0004 {
+		t.Errorf("AGA8 composition must not move helium to nitrogen; got %v", aga8.fractions)
+	}
+}
+
+// контрольные значения ISO 12213-2 (AGA8-92DC), приложение C, для газа 1: его состав совпадает с N1
+func TestAGA8ReferenceValues(t *testing.T) {
+	ctx := n1Context()
+	for _, tc := range []struct{ p, t, z float64 }{
+		{6, 270, 0.84053},
+		{12, 330, 0.88383},
+	} {
+		ctx.p, ctx.t = tc.p, tc.t
+		if r := mustAGA8(ctx); !almostEqual(r.z, tc.z, 0.5e-5) {
+			t.Errorf("Wrong AGA8 z at p = %g, t = %g; actual = %.6f, expected = %.5f", tc.p, tc.t, r.z, tc.z)
+		}
+	}
+}
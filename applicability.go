@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Проверка исходных данных на попадание в область применения ГОСТ 30319.3: давление до 30 МПа,
+// температура от 250 до 350 К и границы молярных долей компонентов. Расчет вне области применения
+// выполняется, но сопровождается предупреждением, а в строгом режиме (-strict) запрещается.
+
+// границы величины, включительно
+type applicabilityRange struct {
+	min float64
+	max float64
+}
+
+var (
+	gost3PressureRange    = applicabilityRange{0, 30}
+	gost3TemperatureRange = applicabilityRange{250, 350}
+)
+
+// пределы расчета, в отличие от области применения - не предупреждение, а отказ. Уравнения ГОСТ 30319.3
+// и AGA8 описывают газовую фазу: ниже критической температуры метана газ может быть жидкостью, итерации
+// плотности не сходятся или сходятся к нефизичному корню. Кубические уравнения описывают и жидкость,
+// для них нижний предел - тройная точка метана
+var (
+	calcPressureRange         = applicabilityRange{0, 100}
+	calcTemperatureRange      = applicabilityRange{190, 500}
+	cubicCalcTemperatureRange = applicabilityRange{90, 500}
+)
+
+// проверка давления, МПа, и температуры, К, на пределы расчета методом
+func checkCalcLimits(model EquationOfState, p, t float64) error {
+	tRange := calcTemperatureRange
+	if _, ok := model.(*cubicEOS); ok {
+		tRange = cubicCalcTemperatureRange
+	}
+	if !(p > calcPressureRange.min && p <= calcPressureRange.max) {
+		return fmt.Errorf("pressure %g MPa is outside of calculation limits %g..%g MPa", p, calcPressureRange.min, calcPressureRange.max)
+	}
+	if !(t >= tRange.min && t <= tRange.max) {
+		return fmt.Errorf("temperature %g K is outside of calculation limits %g..%g K for model %s", t, tRange.min, tRange.max, model.Name())
+	}
+	return nil
+}
+
+// свойства, которые метод не рассчитывает, равны нулю, остальные должны быть конечными
+func checkFiniteProperties(gp *gasProperties) error {
+	for _, v := range []float64{gp.z, gp.density, gp.k, gp.w, gp.mu} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("calculation produced non-finite properties")
+		}
+	}
+	return nil
+}
+
+// граница суммарной доли группы компонентов, %
+type fractionLimit struct {
+	name       string
+	components []*gasComponent
+	limits     applicabilityRange
+}
+
+// границы молярных долей по области применения ГОСТ 30319.3, для бутанов и пентанов - по сумме изомеров
+var gost3FractionLimits = []fractionLimit{
+	{methane.name, []*gasComponent{&methane}, applicabilityRange{70, 100}},
+	{ethane.name, []*gasComponent{&ethane}, applicabilityRange{0, 10}},
+	{propane.name, []*gasComponent{&propane}, applicabilityRange{0, 3.5}},
+	{"бутаны", []*gasComponent{&iButane, &nButane}, applicabilityRange{0, 1.5}},
+	{"пентаны", []*gasComponent{&iPentane, &nPentane}, applicabilityRange{0, 0.5}},
+	{nHexane.name, []*gasComponent{&nHexane}, applicabilityRange{0, 0.1}},
+	{nitrogen.name, []*gasComponent{&nitrogen}, applicabilityRange{0, 20}},
+	{carbonDioxide.name, []*gasComponent{&carbonDioxide}, applicabilityRange{0, 20}},
+	{helium.name, []*gasComponent{&helium}, applicabilityRange{0, 0.5}},
+	{hydrogen.name, []*gasComponent{&hydrogen}, applicabilityRange{0, 10}},
+}
+
+// результат проверки одной величины
+type rangeCheck struct {
+	name   string
+	unit   string
+	value  float64
+	limits applicabilityRange
+}
+
+func (rc rangeCheck) ok() bool {
+	return rc.value >= rc.limits.min && rc.value <= rc.limits.max
+}
+
+func (rc rangeCheck) String() string {
+	status := "в области применения"
+	if !rc.ok() {
+		status = "вне области применения"
+	}
+	return fmt.Sprintf("%s = %.6g %s: %s %g..%g %s", rc.name, rc.value, rc.unit, status, rc.limits.min, rc.limits.max, rc.unit)
+}
+
+func pressureCheck(p float64) rangeCheck {
+	return rangeCheck{"p", "МПа", p, gost3PressureRange}
+}
+
+func temperatureCheck(t float64) rangeCheck {
+	return rangeCheck{"T", "К", t, gost3TemperatureRange}
+}
+
+// проверка состава в том виде, в котором он задан, до корректировки в initFractions
+func checkComposition(ctx *context) []rangeCheck {
+	checks := make([]rangeCheck, 0, len(gost3FractionLimits))
+	for _, fl := range gost3FractionLimits {
+		total := 0.0
+		for _, cf := range sourceFractions(ctx) {
+			for _, comp := range fl.components {
+				if cf.component == comp {
+					total += cf.fraction * 100
+				}
+			}
+		}
+		checks = append(checks, rangeCheck{fl.name, "%", total, fl.limits})
+	}
+	return checks
+}
+
+// проверка всех исходных данных. Давление и температура проверяются, если заданы:
+// в обратных задачах одна из них рассчитывается
+func checkApplicability(ctx *context) []rangeCheck {
+	var checks []rangeCheck
+	if ctx.p > 0 {
+		checks = append(checks, pressureCheck(ctx.p))
+	}
+	if ctx.t > 0 {
+		checks = append(checks, temperatureCheck(ctx.t))
+	}
+	return append(checks, checkComposition(ctx)...)
+}
+
+// обратная задача с проверкой рассчитанных давления или температуры: до решения они не известны,
+// поэтому решение выводится в буфер и попадает в вывод только после проверки. В строгом режиме
+// результат вне области применения не выводится, а возвращается ошибка
+func checkSolved(solve func(ctx *context, out *output) error, strict bool) func(ctx *context, out *output) error {
+	return func(ctx *context, out *output) error {
+		p, t := ctx.p, ctx.t
+		var buf bytes.Buffer
+		if err := solve(ctx, newStreamOutput(&buf, out.level)); err != nil {
+			// промежуточные величины и итерации помогают понять, почему решение не найдено
+			out.print(buf.String())
+			return err
+		}
+		var checks []rangeCheck
+		if ctx.p != p {
+			checks = append(checks, pressureCheck(ctx.p))
+		}
+		if ctx.t != t {
+			checks = append(checks, temperatureCheck(ctx.t))
+		}
+		if strict {
+			if err := strictApplicability(checks); err != nil {
+				return err
+			}
+		}
+		out.print(buf.String())
+		out.writeApplicability(checks)
+		return nil
+	}
+}
+
+// величины вне области применения
+func outOfRange(checks []rangeCheck) []rangeCheck {
+	var result []rangeCheck
+	for _, rc := range checks {
+		if !rc.ok() {
+			result = append(result, rc)
+		}
+	}
+	return result
+}
+
+// предупреждения для вывода, по одному на величину вне области применения
+func applicabilityWarnings(checks []rangeCheck) []string {
+	var warnings []string
+	for _, rc := range outOfRange(checks) {
+		warnings = append(warnings, "Предупреждение: "+rc.String())
+	}
+	return warnings
+}
+
+// ошибка строгого режима, если хотя бы одна величина вне области применения
+func strictApplicability(checks []rangeCheck) error {
+	bad := outOfRange(checks)
+	if len(bad) == 0 {
+		return nil
+	}
+	names := make([]string, len(bad))
+	for i, rc := range bad {
+		names[i] = fmt.Sprintf("%s = %.6g %s", rc.name, rc.value, rc.unit)
+	}
+	return errors.New("input is outside of GOST 30319.3 applicability range: " + strings.Join(names, ", "))
+}
+
+// методы, для которых действует область применения ГОСТ 30319.3
+func hasGost3Applicability(model EquationOfState) bool {
+	switch model.(type) {
+	case gost3Model, aga8Model:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestApplicability(t *testing.T) {
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 283.15
+	checks := checkApplicability(ctx)
+	if len(checks) != 2+len(gost3FractionLimits) {
+		t.Fatalf("Every input must be classified; actual checks = %d", len(checks))
+	}
+	if err := strictApplicability(checks); err != nil {
+		t.Errorf("N1 must be in range: %v", err)
+	}
+	ctx.p, ctx.t = 35, 240
+	if bad := outOfRange(checkApplicability(ctx)); len(bad) != 2 {
+		t.Errorf("Expected pressure and temperature out of range; actual = %v", bad)
+	}
+	// бутаны проверяются по сумме изомеров
+	ctx = &context{fractions: []componentFraction{{&methane, 0.98}, {&iButane, 0.01}, {&nButane, 0.01}}}
+	ctx.initFractions()
+	bad := outOfRange(checkApplicability(ctx))
+	if len(bad) != 1 || bad[0].name != "бутаны" {
+		t.Errorf("Expected butanes out of range; actual = %v", bad)
+	}
+	if strictApplicability(bad) == nil {
+		t.Error("Expected strict mode error")
+	}
+}
+
+func TestTableApplicabilityWarnings(t *testing.T) {
+	ctx := n1Context()
+	pt, err := getPropertyTable(ctx, gost3Model{}, []float64{10, 32}, []float64{0}, tableProperties[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pt.warnings) != 1 {
+		t.Fatalf("Expected warning for 32 MPa; actual = %v", pt.warnings)
+	}
+	var buf bytes.Buffer
+	if err := pt.writeJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Warnings) != 1 {
+		t.Errorf("Warnings must be written to JSON; actual = %v, err = %v", decoded.Warnings, err)
+	}
+	pt, err = getPropertyTable(ctx, newPengRobinson(defaultKij, rootGibbs), []float64{32}, []float64{0}, tableProperties[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pt.warnings) != 0 {
+		t.Errorf("GOST 30319.3 range must not apply to cubic equations; actual = %v", pt.warnings)
+	}
+}
+
+func TestTableCSVWarnings(t *testing.T) {
+	pt, err := getPropertyTable(n1Context(), gost3Model{}, []float64{10, 32}, []float64{0, 20}, tableProperties[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := pt.writeCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "# Предупреждение") {
+		t.Errorf("Warnings must be written as CSV comments; actual = %q", buf.String())
+	}
+	r := csv.NewReader(&buf)
+	r.Comment = '#'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("All CSV rows must have the same number of fields: %v", err)
+	}
+	if len(records) != 2*(len(pt.p)+2) {
+		t.Errorf("Wrong number of CSV rows: %d", len(records))
+	}
+}
+
+func TestSolvedApplicability(t *testing.T) {
+	solved := func(rho float64, strict bool) (string, error) {
+		ctx := n1Context()
+		ctx.rho, ctx.t = rho, 283.15
+		var buf bytes.Buffer
+		err := checkSolved(calcRhoT, strict)(ctx, newStreamOutput(&buf, quiet))
+		return buf.String(), err
+	}
+	if text, err := solved(50, false); err != nil || strings.Contains(text, "Предупреждение") {
+		t.Errorf("Unexpected warning for pressure inside the range: %q, %v", text, err)
+	}
+	if text, err := solved(300, false); err != nil || !strings.Contains(text, "Предупреждение: p =") {
+		t.Errorf("Expected warning for computed pressure above 30 MPa: %q, %v", text, err)
+	}
+	if text, err := solved(300, true); err == nil || text != "" {
+		t.Errorf("Strict mode must refuse computed pressure above 30 MPa without output: %q, %v", text, err)
+	}
+	if text, err := solved(50, true); err != nil || !strings.Contains(text, "p =") {
+		t.Errorf("Strict mode must output result inside the range: %q, %v", text, err)
+	}
+}
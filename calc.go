@@ -1,6 +1,10 @@
 package main
 
-import "math"
+import (
+	"errors"
+	"fmt"
+	"math"
+)
 
 // сумма от i=start до end
 func sum(start, end int32, getEl func(i int32) float64) float64 {
@@ -42,25 +46,23 @@ func getMm(c *context) float64 {
 	})
 }
 
-// функции молярных долей компонентов Dn и Un
-func getDU(ctx *context, kx float64) (d []float64, u []float64) {
+// смесевые параметры энергии G, квадрупольности Q, поляризуемости F и параметр V, используемые в Cn
+func getMixtureParams(ctx *context) (g, q, f, v float64) {
 	nc := ctx.length()
-	d = make([]float64, 58)
-	u = make([]float64, 58)
-	g := sum(0, nc, func(i int32) float64 {
+	g = sum(0, nc, func(i int32) float64 {
 		return ctx.fraction(i) * ctx.component(i).g
 	}) + sum(0, nc-1, func(i int32) float64 {
 		return sum(i+1, nc, func(j int32) float64 {
 			return ctx.fraction(i) * ctx.fraction(j) * (ctx.gBin(i, j) - 1) * (ctx.component(i).g + ctx.component(j).g)
 		})
 	})
-	q := sum(0, nc, func(i int32) float64 {
+	q = sum(0, nc, func(i int32) float64 {
 		return ctx.fraction(i) * ctx.component(i).q
 	})
-	f := sum(0, nc, func(i int32) float64 {
+	f = sum(0, nc, func(i int32) float64 {
 		return math.Pow(ctx.fraction(i), 2) * ctx.component(i).f
 	})
-	v := math.Pow(
+	v = math.Pow(
 		math.Pow(
 			sum(0, nc, func(i int32) float64 {
 				return ctx.fraction(i) * math.Pow(ctx.component(i).e, 5.0/2)
@@ -75,8 +77,14 @@ func getDU(ctx *context, kx float64) (d []float64, u []float64) {
 		}),
 		1.0/5,
 	)
-	e := make([][]float64, nc)
-	gBin := make([][]float64, nc)
+	return
+}
+
+// параметры энергии Eij и ориентации Gij пар компонентов
+func getPairParams(ctx *context) (e, gBin [][]float64) {
+	nc := ctx.length()
+	e = make([][]float64, nc)
+	gBin = make([][]float64, nc)
 	for i := int32(0); i < nc; i++ {
 		e[i] = make([]float64, nc)
 		gBin[i] = make([]float64, nc)
@@ -85,18 +93,35 @@ func getDU(ctx *context, kx float64) (d []float64, u []float64) {
 			gBin[i][j] = ctx.gBin(i, j) * (ctx.component(i).g + ctx.component(j).g) / 2
 		}
 	}
-	for n := 0; n < 58; n++ {
-		bBin := make([][]float64, nc)
-		for i := int32(0); i < nc; i++ {
-			bBin[i] = make([]float64, nc)
-			for j := int32(0); j < nc; j++ {
-				bBin[i][j] = math.Pow(gBin[i][j]+1-noDimensions.g[n], noDimensions.g[n]) *
-					math.Pow(ctx.component(i).q*ctx.component(j).q+1-noDimensions.q[n], noDimensions.q[n]) *
-					math.Pow(math.Sqrt(ctx.component(i).f*ctx.component(j).f)+1-noDimensions.f[n], noDimensions.f[n]) *
-					math.Pow(ctx.component(i).s*ctx.component(j).s+1-noDimensions.s[n], noDimensions.s[n]) *
-					math.Pow(ctx.component(i).w*ctx.component(j).w+1-noDimensions.w[n], noDimensions.w[n])
-			}
+	return
+}
+
+// коэффициенты Bnij для слагаемого n
+func getBnij(ctx *context, gBin [][]float64, n int) [][]float64 {
+	nc := ctx.length()
+	bBin := make([][]float64, nc)
+	for i := int32(0); i < nc; i++ {
+		bBin[i] = make([]float64, nc)
+		for j := int32(0); j < nc; j++ {
+			bBin[i][j] = math.Pow(gBin[i][j]+1-noDimensions.g[n], noDimensions.g[n]) *
+				math.Pow(ctx.component(i).q*ctx.component(j).q+1-noDimensions.q[n], noDimensions.q[n]) *
+				math.Pow(math.Sqrt(ctx.component(i).f*ctx.component(j).f)+1-noDimensions.f[n], noDimensions.f[n]) *
+				math.Pow(ctx.component(i).s*ctx.component(j).s+1-noDimensions.s[n], noDimensions.s[n]) *
+				math.Pow(ctx.component(i).w*ctx.component(j).w+1-noDimensions.w[n], noDimensions.w[n])
 		}
+	}
+	return bBin
+}
+
+// функции молярных долей компонентов Dn и Un
+func getDU(ctx *context, kx float64) (d []float64, u []float64) {
+	nc := ctx.length()
+	d = make([]float64, 58)
+	u = make([]float64, 58)
+	g, q, f, v := getMixtureParams(ctx)
+	e, gBin := getPairParams(ctx)
+	for n := 0; n < 58; n++ {
+		bBin := getBnij(ctx, gBin, n)
 		b := sum(0, nc, func(i int32) float64 {
 			return sum(0, nc, func(j int32) float64 {
 				return ctx.fraction(i) * ctx.fraction(j) * bBin[i][j] *
@@ -153,7 +178,7 @@ func getA1(ctx *context, sigma float64, tau float64, d, u []float64) float64 {
 }
 
 // безразмерный комплекс А2
-func getA2(ctx *context, sigma, tau float64, d, u [58]float64) float64 {
+func getA2(ctx *context, sigma, tau float64, d, u []float64) float64 {
 	return sum(0, 58, func(n int32) float64 {
 		return noDimensions.a[n] * math.Pow(sigma, noDimensions.b[n]) * math.Pow(tau, -noDimensions.u[n]) *
 			(1 - noDimensions.u[n]) * (noDimensions.b[n]*d[n] +
@@ -163,10 +188,10 @@ func getA2(ctx *context, sigma, tau float64, d, u [58]float64) float64 {
 }
 
 // безразмерный комплекс А3
-func getA3(ctx *context, sigma, tau float64, d, u [58]float64) float64 {
+func getA3(ctx *context, sigma, tau float64, d, u []float64) float64 {
 	return sum(0, 58, func(n int32) float64 {
 		return noDimensions.a[n] * math.Pow(sigma, noDimensions.b[n]) * math.Pow(tau, -noDimensions.u[n]) *
-			(1 - noDimensions.u[n]) * (d[n] + u[n]*math.Exp(-noDimensions.c[n]*math.Pow(sigma, noDimensions.k[n])))
+			noDimensions.u[n] * (1 - noDimensions.u[n]) * (d[n] + u[n]*math.Exp(-noDimensions.c[n]*math.Pow(sigma, noDimensions.k[n])))
 	})
 }
 
@@ -178,6 +203,8 @@ type sigmaIteration struct {
 	dSigma float64
 	// расчетное приведенное давление
 	piCalc float64
+	// относительная невязка расчетного приведенного давления
+	residual float64
 }
 
 // приведенное давление
@@ -191,20 +218,25 @@ func getTau(ctx *context) float64 {
 }
 
 // расчет приведенной плотности в итерационном процессе
-func getSigma(ctx *context, kx, pi, tau, initialSigma float64, d, u []float64) []sigmaIteration {
+// с ограничением числа итераций: вдали от газовой области, например при криогенных температурах,
+// метод Ньютона может не сходиться или уходить в нефизичную область
+func getSigma(ctx *context, kx, pi, tau, initialSigma float64, d, u []float64) ([]sigmaIteration, error) {
 	sigma := initialSigma
 	var iters []sigmaIteration
-	for {
+	for len(iters) < maxSolverIterations {
 		dSigma := (pi/tau - (1+getA0(ctx, sigma, tau, d, u))*sigma) / (1 + getA1(ctx, sigma, tau, d, u))
 		sigma += dSigma
+		if sigma <= 0 || math.IsNaN(sigma) || math.IsInf(sigma, 0) {
+			return iters, errors.New("density iterations diverged")
+		}
 		piCalc := sigma * tau * (1 + getA0(ctx, sigma, tau, d, u))
-		end := math.Abs((piCalc-pi)/pi) < math.Pow10(-6)
-		iters = append(iters, sigmaIteration{sigma, dSigma, piCalc})
-		if end {
-			break
+		residual := math.Abs((piCalc - pi) / pi)
+		iters = append(iters, sigmaIteration{sigma, dSigma, piCalc, residual})
+		if residual < math.Pow10(-6) {
+			return iters, nil
 		}
 	}
-	return iters
+	return iters, fmt.Errorf("density iterations did not converge in %d steps", maxSolverIterations)
 }
 
 // начальное приближение приведенной плотности
@@ -226,18 +258,26 @@ func getZ(ctx *context, sigma, tau float64, d, u []float64) float64 {
 func getCp0r(ctx *context) float64 {
 	n := ctx.length()
 	// tau^-1
-	theta := 1 / (ctx.p / Lt)
+	theta := 1 / (ctx.t / Lt)
 	return sum(0, n, func(i int32) float64 {
 		c := ctx.component(i)
 		cp0ri := c.b0 +
-			c.c0*math.Pow(c.d0*theta/math.Sinh(c.d0*theta), 2) +
-			c.e0*math.Pow(c.f0*theta/math.Cosh(c.f0*theta), 2) +
-			c.g0*math.Pow(c.h0*theta/math.Sinh(c.h0*theta), 2) +
-			c.i0*math.Pow(c.j0*theta/math.Cosh(c.j0*theta), 2)
+			cp0Term(c.c0, c.d0*theta, math.Sinh) +
+			cp0Term(c.e0, c.f0*theta, math.Cosh) +
+			cp0Term(c.g0, c.h0*theta, math.Sinh) +
+			cp0Term(c.i0, c.j0*theta, math.Cosh)
 		return ctx.fraction(i) * cp0ri
 	})
 }
 
+// слагаемое теплоемкости вида coef*(x/fn(x))^2, у части компонентов коэффициенты нулевые
+func cp0Term(coef, x float64, fn func(float64) float64) float64 {
+	if coef == 0 || x == 0 {
+		return 0
+	}
+	return coef * math.Pow(x/fn(x), 2)
+}
+
 // расчет адиабаты
 func getK(ctx *context, sigma, tau, a1, a2, a3, cp0r float64, d, u []float64) float64 {
 	z := getZ(ctx, sigma, tau, d, u)
@@ -278,7 +318,7 @@ func getTpc(ctx *context, pMolPc float64) float64 {
 			ci := ctx.component(i)
 			cj := ctx.component(j)
 			return ctx.fraction(i) * ctx.fraction(j) * math.Pow(
-				math.Pow(ci.m/ci.pcr, 1.0/3)+math.Pow(cj.m/ci.pcr, 1.0/3), 3) *
+				math.Pow(ci.m/ci.pcr, 1.0/3)+math.Pow(cj.m/cj.pcr, 1.0/3), 3) *
 				math.Pow(ci.tcr*cj.tcr, 1.0/2)
 		})
 	})
@@ -319,7 +359,7 @@ func getMu0Comp(ctx *context) []float64 {
 	n := ctx.length()
 	u0 := make([]float64, n)
 	for i := range u0 {
-		u0[i] = sum(0, 3, func(k int32) float64 {
+		u0[i] = sum(0, 4, func(k int32) float64 {
 			return ctx.component(int32(i)).a[k] * math.Pow(ctx.t/100, float64(k))
 		})
 	}
@@ -354,3 +394,179 @@ func getPpc(ctx *context, pMolPc, tpc float64) float64 {
 func getMu(ctx *context, mu0, mm, ppc, tpc, deltaU float64) float64 {
 	return mu0 + 2.63094*math.Pow(mm, 1.0/2)*math.Pow(ppc, 2.0/3)/math.Pow(tpc, 1.0/6)*deltaU
 }
+
+// параметры смеси, не зависящие от давления и температуры
+type mixture struct {
+	kx  float64
+	p0m float64
+	mm  float64
+	d   []float64
+	u   []float64
+	// псевдокритические параметры и параметры преобразований для расчета вязкости
+	pMolPc float64
+	tpc    float64
+	ppc    float64
+	phi    [6]float64
+}
+
+// расчет параметров смеси, которые достаточно вычислить один раз для заданного состава
+func newMixture(ctx *context) *mixture {
+	m := &mixture{}
+	m.kx = getKx(ctx)
+	m.p0m = getP0m(m.kx)
+	m.mm = getMm(ctx)
+	m.d, m.u = getDU(ctx, m.kx)
+	m.pMolPc = getPMolPc(ctx)
+	m.tpc = getTpc(ctx, m.pMolPc)
+	m.ppc = getPpc(ctx, m.pMolPc, m.tpc)
+	m.phi = getPhi(ctx)
+	return m
+}
+
+// свойства газа при заданных давлении и температуре
+type gasProperties struct {
+	// коэффициент сжимаемости
+	z float64
+	// плотность, кг/м^3
+	density float64
+	// показатель адиабаты
+	k float64
+	// скорость звука, м/с
+	w float64
+	// динамическая вязкость, мкПа*с
+	mu float64
+	// летучести компонентов в порядке состава, МПа, рассчитываются только кубическими уравнениями
+	fugacity []float64
+	// итерации расчета приведенной плотности
+	iters []sigmaIteration
+}
+
+// расчет свойств газа при давлении и температуре из контекста
+func getProperties(ctx *context, m *mixture) (*gasProperties, error) {
+	pi := getPi(ctx, m.p0m)
+	tau := getTau(ctx)
+	iters, err := getSigma(ctx, m.kx, pi, tau, getInitialSigma(ctx, m.kx, m.d, m.u), m.d, m.u)
+	if err != nil {
+		return nil, err
+	}
+	sigma := iters[len(iters)-1].sigma
+	a1 := getA1(ctx, sigma, tau, m.d, m.u)
+	a2 := getA2(ctx, sigma, tau, m.d, m.u)
+	a3 := getA3(ctx, sigma, tau, m.d, m.u)
+	cp0r := getCp0r(ctx)
+	density := getP(ctx, m.kx, m.mm, sigma)
+	deltaU := getDeltaU(m.phi, getOmegaM(getPMol(density, m.mm), m.pMolPc), getTauM(ctx, m.tpc))
+	return &gasProperties{
+		z:       getZ(ctx, sigma, tau, m.d, m.u),
+		density: density,
+		k:       getK(ctx, sigma, tau, a1, a2, a3, cp0r, m.d, m.u),
+		w:       getU(ctx, m.mm, a1, a2, a3, cp0r),
+		mu:      getMu(ctx, getMu0(ctx, getMu0Comp(ctx)), m.mm, m.ppc, m.tpc, deltaU),
+		iters:   iters,
+	}, nil
+}
+
+// опорное состояние для энтальпии и энтропии: идеальный газ при 298,15 К и 0,101325 МПа
+const (
+	refT = 298.15
+	refP = 0.101325
+)
+
+// сумма слагаемых приведенной свободной энергии с множителем mul(n)
+func sumAlphaTerms(sigma, tau float64, d, u []float64, mul func(n int32) float64) float64 {
+	return sum(0, 58, func(n int32) float64 {
+		return mul(n) * noDimensions.a[n] * math.Pow(sigma, noDimensions.b[n]) * math.Pow(tau, -noDimensions.u[n]) *
+			(d[n] + u[n]*math.Exp(-noDimensions.c[n]*math.Pow(sigma, noDimensions.k[n])))
+	})
+}
+
+// приведенная остаточная свободная энергия Гельмгольца
+func getAlphaR(ctx *context, sigma, tau float64, d, u []float64) float64 {
+	return sumAlphaTerms(sigma, tau, d, u, func(n int32) float64 { return 1 })
+}
+
+// приведенная остаточная внутренняя энергия u_r/RT
+func getUr(ctx *context, sigma, tau float64, d, u []float64) float64 {
+	return sumAlphaTerms(sigma, tau, d, u, func(n int32) float64 { return noDimensions.u[n] })
+}
+
+// первообразные слагаемых теплоемкости вида coef*(x/sinh(x))^2 и coef*(x/cosh(x))^2, x = c/T,
+// для энтальпии (h0/R) и энтропии (s0/R)
+func h0SinhTerm(coef, c, t float64) float64 {
+	if coef == 0 || c == 0 {
+		return 0
+	}
+	return coef * c / math.Tanh(c/t)
+}
+
+func h0CoshTerm(coef, c, t float64) float64 {
+	if coef == 0 || c == 0 {
+		return 0
+	}
+	return -coef * c * math.Tanh(c/t)
+}
+
+func s0SinhTerm(coef, c, t float64) float64 {
+	if coef == 0 || c == 0 {
+		return 0
+	}
+	x := c / t
+	return coef * (x/math.Tanh(x) - math.Log(math.Sinh(x)))
+}
+
+func s0CoshTerm(coef, c, t float64) float64 {
+	if coef == 0 || c == 0 {
+		return 0
+	}
+	x := c / t
+	return -coef * (x*math.Tanh(x) - math.Log(math.Cosh(x)))
+}
+
+// безразмерная энтальпия компонента в идеально-газовом состоянии h0/R, К, без опорной константы
+func (c *gasComponent) h0r(t float64) float64 {
+	return c.b0*t + h0SinhTerm(c.c0, c.d0, t) + h0CoshTerm(c.e0, c.f0, t) +
+		h0SinhTerm(c.g0, c.h0, t) + h0CoshTerm(c.i0, c.j0, t)
+}
+
+// безразмерная энтропия компонента в идеально-газовом состоянии при опорном давлении s0/R, без опорной константы
+func (c *gasComponent) s0r(t float64) float64 {
+	return c.b0*math.Log(t) + s0SinhTerm(c.c0, c.d0, t) + s0CoshTerm(c.e0, c.f0, t) +
+		s0SinhTerm(c.g0, c.h0, t) + s0CoshTerm(c.i0, c.j0, t)
+}
+
+// удельная энтальпия газа, кДж/кг
+func getH(ctx *context, mm, sigma, tau float64, d, u []float64) float64 {
+	n := ctx.length()
+	h0 := sum(0, n, func(i int32) float64 {
+		c := ctx.component(i)
+		return ctx.fraction(i) * (c.h0r(ctx.t) - c.h0r(refT))
+	})
+	hr := ctx.t * (getA0(ctx, sigma, tau, d, u) + getUr(ctx, sigma, tau, d, u))
+	return R * (h0 + hr) / mm
+}
+
+// удельная энтропия газа, кДж/(кг*К)
+func getS(ctx *context, mm, sigma, tau float64, d, u []float64) float64 {
+	n := ctx.length()
+	s0 := sum(0, n, func(i int32) float64 {
+		// компоненты, доли которых обнулены при корректировке, в энтропию смешения не входят
+		x := ctx.fraction(i)
+		if x == 0 {
+			return 0
+		}
+		c := ctx.component(i)
+		return x * (c.s0r(ctx.t) - c.s0r(refT) - math.Log(x))
+	})
+	z := getZ(ctx, sigma, tau, d, u)
+	sr := getUr(ctx, sigma, tau, d, u) - getAlphaR(ctx, sigma, tau, d, u)
+	return R * (s0 - math.Log(ctx.p/refP) + math.Log(z) + sr) / mm
+}
+
+// удельная изобарная теплоемкость газа, кДж/(кг*К)
+func getCp(ctx *context, mm, sigma, tau float64, d, u []float64) float64 {
+	a1 := getA1(ctx, sigma, tau, d, u)
+	a2 := getA2(ctx, sigma, tau, d, u)
+	a3 := getA3(ctx, sigma, tau, d, u)
+	cvr := getCp0r(ctx) - 1 + a3
+	return R * (cvr + math.Pow(1+a2, 2)/(1+a1)) / mm
+}
@@ -0,0 +1,82 @@
+package main
+
+import "math"
+
+// температуры сгорания, для которых заданы теплоты сгорания компонентов, °С
+var combustionTemperatures = [4]float64{25, 20, 15, 0}
+
+// теплота испарения воды при температурах сгорания 25, 20, 15 и 0 °С, кДж/моль
+var waterVaporizationHeat = [4]float64{44.016, 44.224, 44.433, 45.064}
+
+// молярная масса сухого воздуха, кг/кмоль
+const airMolarMass = 28.9626
+
+// коэффициент сжимаемости сухого воздуха при стандартном давлении и температуре t, К
+func airZ(t float64) float64 {
+	switch math.Round(t - 273.15) {
+	case 0:
+		return 0.99941
+	case 15:
+		return 0.99958
+	default:
+		return 0.99963
+	}
+}
+
+func combustionTemperatureIndex(tComb float64) int {
+	for i, t := range combustionTemperatures {
+		if t == tComb {
+			return i
+		}
+	}
+	return -1
+}
+
+// высшая молярная теплота сгорания компонента, кДж/моль
+func (c *gasComponent) grossHeat(tComb float64) float64 {
+	return c.hs[combustionTemperatureIndex(tComb)]
+}
+
+// низшая молярная теплота сгорания компонента, кДж/моль
+func (c *gasComponent) netHeat(tComb float64) float64 {
+	return c.grossHeat(tComb) - c.water*waterVaporizationHeat[combustionTemperatureIndex(tComb)]
+}
+
+// теплота сгорания и связанные с ней показатели по ГОСТ 31369 / ISO 6976
+type calorificValues struct {
+	// высшая и низшая молярная теплота сгорания, МДж/кмоль
+	grossMolar float64
+	netMolar   float64
+	// высшая и низшая массовая теплота сгорания, МДж/кг
+	grossMass float64
+	netMass   float64
+	// высшая и низшая объемная теплота сгорания при стандартных условиях, МДж/м^3
+	grossVolume float64
+	netVolume   float64
+	// относительная плотность по воздуху
+	relativeDensity float64
+	// высшее и низшее число Воббе, МДж/м^3
+	grossWobbe float64
+	netWobbe   float64
+}
+
+// расчет теплоты сгорания при температуре сгорания и стандартных условиях из sc,
+// sp - свойства газа при стандартных условиях
+func getCalorificValues(ctx *context, m *mixture, sc *standardConditions, sp *standardProperties) *calorificValues {
+	n := ctx.length()
+	cv := &calorificValues{}
+	cv.grossMolar = sum(0, n, func(i int32) float64 {
+		return ctx.fraction(i) * ctx.component(i).grossHeat(sc.tComb)
+	})
+	cv.netMolar = sum(0, n, func(i int32) float64 {
+		return ctx.fraction(i) * ctx.component(i).netHeat(sc.tComb)
+	})
+	cv.grossMass = cv.grossMolar / m.mm
+	cv.netMass = cv.netMolar / m.mm
+	cv.grossVolume = cv.grossMolar / sp.molarVolume
+	cv.netVolume = cv.netMolar / sp.molarVolume
+	cv.relativeDensity = m.mm * airZ(sc.t) / (airMolarMass * sp.z)
+	cv.grossWobbe = cv.grossVolume / math.Sqrt(cv.relativeDensity)
+	cv.netWobbe = cv.netVolume / math.Sqrt(cv.relativeDensity)
+	return cv
+}
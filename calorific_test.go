@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalorificValuesMethane(t *testing.T) {
+	ctx := &context{fractions: []componentFraction{{&methane, 1}}}
+	ctx.initFractions()
+	m := newMixture(ctx)
+	sc, err := newStandardConditions(15, 15, "sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := mustStandardProperties(ctx, m, sc)
+	cv := getCalorificValues(ctx, m, sc, sp)
+	// ISO 6976: метан, 15 °С - высшая 891,56 кДж/моль, низшая 802,69 кДж/моль
+	if !almostEqual(cv.grossMolar, 891.56, 0.005) {
+		t.Errorf("Wrong gross molar heat; actual = %f, expected = %f", cv.grossMolar, 891.56)
+	}
+	if !almostEqual(cv.netMolar, 802.69, 0.01) {
+		t.Errorf("Wrong net molar heat; actual = %f, expected = %f", cv.netMolar, 802.69)
+	}
+	if !almostEqual(cv.grossMass, 891.56/16.043, 1e-9) {
+		t.Errorf("Wrong gross mass heat; actual = %f", cv.grossMass)
+	}
+	if !almostEqual(cv.grossVolume, 37.78, 0.01) {
+		t.Errorf("Wrong gross volumetric heat; actual = %f, expected = %f", cv.grossVolume, 37.78)
+	}
+	if !almostEqual(cv.relativeDensity, 0.5548, 0.0001) {
+		t.Errorf("Wrong relative density; actual = %f, expected = %f", cv.relativeDensity, 0.5548)
+	}
+	if !almostEqual(cv.grossWobbe, cv.grossVolume/math.Sqrt(cv.relativeDensity), 1e-9) {
+		t.Errorf("Wrong Wobbe index; actual = %f", cv.grossWobbe)
+	}
+}
+
+func TestNonCombustibleComponents(t *testing.T) {
+	for _, c := range []*gasComponent{&nitrogen, &carbonDioxide, &helium} {
+		for _, tc := range combustionTemperatures {
+			if c.grossHeat(tc) != 0 || c.netHeat(tc) != 0 {
+				t.Errorf("Non-zero heat for %s", c.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import "math"
+
+// Расчет для C-библиотеки (-buildmode=c-shared, см. capi_export.go и capi/gascomponents.h).
+// Идентификаторы компонентов, методов и коды ошибок - часть ABI и должны совпадать с заголовочным файлом:
+// новые значения только добавляются в конец.
+
+// коды ошибок
+const (
+	capiOK          = 0
+	capiArgument    = 1
+	capiComposition = 2
+	capiModel       = 3
+	capiCalculation = 4
+)
+
+// компоненты по идентификаторам, начиная с 1
+var capiComponents = []*gasComponent{
+	nil,
+	&methane,
+	&ethane,
+	&propane,
+	&iButane,
+	&nButane,
+	&iPentane,
+	&nPentane,
+	&nHexane,
+	&nitrogen,
+	&carbonDioxide,
+	&helium,
+	&hydrogen,
+}
+
+// методы по идентификаторам, начиная с 0
+var capiModels = []string{"gost3", "aga8", "nx19", "gerg91", "pr", "srk"}
+
+// коды ошибок по кодам ошибок API
+var capiErrorCodes = map[string]int32{
+	"bad_request":        capiArgument,
+	"bad_composition":    capiComposition,
+	"unknown_model":      capiModel,
+	"calculation_failed": capiCalculation,
+}
+
+// расчет свойств: доли компонентов в %, давление в МПа, температура в К.
+// Свойства, не рассчитываемые методом, равны NaN
+func calcCAPI(ids []int32, fractions []float64, p, t float64, model int32) (result *propertiesResponse, code int32) {
+	if model < 0 || int(model) >= len(capiModels) {
+		return nil, capiModel
+	}
+	if math.IsNaN(p) || math.IsNaN(t) {
+		return nil, capiArgument
+	}
+	tc := t - 273.15
+	req := &propertiesRequest{
+		Composition: make(map[string]float64, len(ids)),
+		P:           &p,
+		T:           &tc,
+		Model:       capiModels[model],
+	}
+	for i, id := range ids {
+		if id <= 0 || int(id) >= len(capiComponents) {
+			return nil, capiComposition
+		}
+		name := capiComponents[id].name
+		if _, ok := req.Composition[name]; ok {
+			return nil, capiComposition
+		}
+		req.Composition[name] = fractions[i]
+	}
+	// паника расчета не должна выходить за границу вызова из C
+	defer func() {
+		if r := recover(); r != nil {
+			result, code = nil, capiCalculation
+		}
+	}()
+	resp, e := (&server{kij: defaultKij}).calcProperties(req)
+	if e != nil {
+		if code, ok := capiErrorCodes[e.Code]; ok {
+			return nil, code
+		}
+		return nil, capiCalculation
+	}
+	// ноль от метода, который свойство рассчитывает, остается нулем
+	if m, err := newModel(req.Model, &modelOptions{kij: defaultKij}); err == nil && !hasExtendedProperties(m) {
+		resp.K, resp.W, resp.Mu = math.NaN(), math.NaN(), math.NaN()
+	}
+	return resp, capiOK
+}
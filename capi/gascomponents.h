@@ -0,0 +1,84 @@
+/*
+ * Свойства природного газа по ГОСТ 30319.2, ГОСТ 30319.3, AGA8 и кубическим уравнениям состояния.
+ *
+ * Сборка библиотеки:
+ *     go build -tags capi -buildmode=c-shared -o libgascomponents.so .
+ *
+ * Значения идентификаторов и кодов ошибок не меняются между версиями, новые только добавляются.
+ * Функции можно вызывать одновременно из нескольких потоков.
+ */
+#ifndef GAS_COMPONENTS_H
+#define GAS_COMPONENTS_H
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+#define GC_ABI_VERSION 1
+
+/* идентификаторы компонентов */
+enum {
+	GC_METHANE = 1,
+	GC_ETHANE = 2,
+	GC_PROPANE = 3,
+	GC_I_BUTANE = 4,
+	GC_N_BUTANE = 5,
+	GC_I_PENTANE = 6,
+	GC_N_PENTANE = 7,
+	GC_N_HEXANE = 8,
+	GC_NITROGEN = 9,
+	GC_CARBON_DIOXIDE = 10,
+	GC_HELIUM = 11,
+	GC_HYDROGEN = 12
+};
+
+/* методы расчета */
+enum {
+	GC_MODEL_GOST3 = 0,  /* ГОСТ 30319.3 */
+	GC_MODEL_AGA8 = 1,   /* AGA8 */
+	GC_MODEL_NX19 = 2,   /* NX19 мод. по ГОСТ 30319.2 */
+	GC_MODEL_GERG91 = 3, /* GERG-91 мод. по ГОСТ 30319.2 */
+	GC_MODEL_PR = 4,     /* уравнение Пенга-Робинсона */
+	GC_MODEL_SRK = 5     /* уравнение Соаве-Редлиха-Квонга */
+};
+
+/* коды ошибок */
+enum {
+	GC_OK = 0,
+	GC_ERR_ARGUMENT = 1,    /* неверные аргументы: нулевые указатели, давление или температура */
+	GC_ERR_COMPOSITION = 2, /* неизвестный или повторяющийся компонент, отрицательная доля, неполный состав */
+	GC_ERR_MODEL = 3,       /* неизвестный метод */
+	GC_ERR_CALCULATION = 4  /* расчет не выполнен */
+};
+
+/* свойства газа. Свойства, не рассчитываемые методом, равны NaN */
+typedef struct {
+	double z;       /* коэффициент сжимаемости */
+	double density; /* плотность, кг/м^3 */
+	double k;       /* показатель адиабаты */
+	double w;       /* скорость звука, м/с */
+	double mu;      /* динамическая вязкость, мкПа*с */
+	int warnings;   /* число величин вне области применения ГОСТ 30319.3 */
+} gc_properties;
+
+/* прототипы не нужны при сборке самой библиотеки: cgo объявляет экспортируемые функции без const */
+#ifndef GC_BUILDING_LIBRARY
+
+/* версия ABI, GC_ABI_VERSION */
+int gc_abi_version(void);
+
+/*
+ * Расчет свойств газа.
+ * n - число компонентов, ids и fractions - идентификаторы компонентов и их молярные доли, %;
+ * p - давление, МПа; t - температура, К; model - метод расчета.
+ * Возвращает код ошибки, при ошибке out не изменяется.
+ */
+int gc_calculate(int n, const int *ids, const double *fractions, double p, double t, int model, gc_properties *out);
+
+#endif
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,96 @@
+/*
+ * Проверка C-библиотеки на газах N1-N3 из ГОСТ 30319.3.
+ *
+ *     go build -tags capi -buildmode=c-shared -o libgascomponents.so .
+ *     cc -o gc_test capi/test.c -I capi -L . -lgascomponents -lm -Wl,-rpath,'$ORIGIN'
+ *     ./gc_test
+ */
+#include <math.h>
+#include <stdio.h>
+
+#include "gascomponents.h"
+
+typedef struct {
+	const char *name;
+	int n;
+	int ids[12];
+	double fractions[12];
+} gas;
+
+typedef struct {
+	const gas *g;
+	double p, t;
+	/* ожидаемые плотность, кг/м^3, и коэффициент сжимаемости */
+	double density, z;
+} test_case;
+
+static const gas n1 = {"N1", 10,
+	{GC_METHANE, GC_ETHANE, GC_PROPANE, GC_I_BUTANE, GC_N_BUTANE, GC_I_PENTANE, GC_N_PENTANE, GC_N_HEXANE,
+		GC_NITROGEN, GC_CARBON_DIOXIDE},
+	{96.5, 1.8, 0.45, 0.1, 0.1, 0.05, 0.03, 0.07, 0.3, 0.6}};
+
+static const gas n2 = {"N2", 7,
+	{GC_METHANE, GC_ETHANE, GC_PROPANE, GC_I_BUTANE, GC_N_BUTANE, GC_NITROGEN, GC_CARBON_DIOXIDE},
+	{81.2, 4.3, 0.9, 0.15, 0.15, 5.7, 7.6}};
+
+static const gas n3 = {"N3", 12,
+	{GC_METHANE, GC_ETHANE, GC_PROPANE, GC_I_BUTANE, GC_N_BUTANE, GC_I_PENTANE, GC_N_PENTANE, GC_N_HEXANE,
+		GC_NITROGEN, GC_CARBON_DIOXIDE, GC_HELIUM, GC_HYDROGEN},
+	{86.41, 1.8, 0.45, 0.1, 0.1, 0.03, 0.05, 0.12, 0.34, 0.6, 0.5, 9.5}};
+
+static const test_case cases[] = {
+	{&n1, 5, 250, 49.295, 0.8200},
+	{&n1, 15, 300, 125.53, 0.8050},
+	{&n2, 5, 300, 43.980, 0.9039},
+	{&n2, 30, 250, 342.04, 0.8369},
+	{&n3, 5, 250, 43.206, 0.8602},
+	{&n3, 15, 350, 84.803, 0.9391},
+};
+
+int main(void) {
+	int failed = 0;
+	if (gc_abi_version() != GC_ABI_VERSION) {
+		printf("ABI version mismatch: library %d, header %d\n", gc_abi_version(), GC_ABI_VERSION);
+		return 1;
+	}
+	for (size_t i = 0; i < sizeof(cases) / sizeof(cases[0]); i++) {
+		const test_case *tc = &cases[i];
+		gc_properties props;
+		int code = gc_calculate(tc->g->n, tc->g->ids, tc->g->fractions, tc->p, tc->t, GC_MODEL_GOST3, &props);
+		if (code != GC_OK) {
+			printf("%s p=%g T=%g: error %d\n", tc->g->name, tc->p, tc->t, code);
+			failed++;
+			continue;
+		}
+		int ok = fabs(props.density - tc->density) <= 0.1 && fabs(props.z - tc->z) <= 0.0001;
+		printf("%s p=%g T=%g: density=%.4f z=%.4f %s\n", tc->g->name, tc->p, tc->t, props.density, props.z,
+			ok ? "ok" : "FAIL");
+		if (!ok) {
+			failed++;
+		}
+	}
+
+	/* ошибки */
+	gc_properties props;
+	int unknown[] = {99};
+	double hundred[] = {100};
+	int methane[] = {GC_METHANE};
+	struct {
+		const char *name;
+		int code;
+		int expected;
+	} errors[] = {
+		{"unknown component", gc_calculate(1, unknown, hundred, 5, 300, GC_MODEL_GOST3, &props), GC_ERR_COMPOSITION},
+		{"unknown model", gc_calculate(1, methane, hundred, 5, 300, 99, &props), GC_ERR_MODEL},
+		{"negative pressure", gc_calculate(1, methane, hundred, -1, 300, GC_MODEL_GOST3, &props), GC_ERR_ARGUMENT},
+		{"null output", gc_calculate(1, methane, hundred, 5, 300, GC_MODEL_GOST3, NULL), GC_ERR_ARGUMENT},
+	};
+	for (size_t i = 0; i < sizeof(errors) / sizeof(errors[0]); i++) {
+		int ok = errors[i].code == errors[i].expected;
+		printf("%s: error %d %s\n", errors[i].name, errors[i].code, ok ? "ok" : "FAIL");
+		if (!ok) {
+			failed++;
+		}
+	}
+	return failed != 0;
+}
@@ -0,0 +1,48 @@
+//go:build capi
+
+package main
+
+/*
+#define GC_BUILDING_LIBRARY
+#include "capi/gascomponents.h"
+*/
+import "C"
+
+import "unsafe"
+
+// Экспортируемые функции C-библиотеки, объявления - в capi/gascomponents.h
+
+//export gc_abi_version
+func gc_abi_version() C.int {
+	return C.GC_ABI_VERSION
+}
+
+//export gc_calculate
+func gc_calculate(n C.int, ids *C.int, fractions *C.double, p, t C.double, model C.int, out *C.gc_properties) C.int {
+	if n < 0 || (n > 0 && (ids == nil || fractions == nil)) || out == nil {
+		return capiArgument
+	}
+	goIDs := make([]int32, n)
+	goFractions := make([]float64, n)
+	if n > 0 {
+		for i, id := range unsafe.Slice(ids, n) {
+			goIDs[i] = int32(id)
+		}
+		for i, f := range unsafe.Slice(fractions, n) {
+			goFractions[i] = float64(f)
+		}
+	}
+	resp, code := calcCAPI(goIDs, goFractions, float64(p), float64(t), int32(model))
+	if code != capiOK {
+		return C.int(code)
+	}
+	*out = C.gc_properties{
+		z:        C.double(resp.Z),
+		density:  C.double(resp.Density),
+		k:        C.double(resp.K),
+		w:        C.double(resp.W),
+		mu:       C.double(resp.Mu),
+		warnings: C.int(len(resp.Warnings)),
+	}
+	return capiOK
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalcCAPI(t *testing.T) {
+	var ids []int32
+	var fractions []float64
+	for _, cf := range n1Fractions {
+		for id, comp := range capiComponents {
+			if comp == cf.component {
+				ids = append(ids, int32(id))
+			}
+		}
+		fractions = append(fractions, cf.fraction*100)
+	}
+	resp, code := calcCAPI(ids, fractions, 5, 250, 0)
+	if code != capiOK {
+		t.Fatalf("Unexpected error code %d", code)
+	}
+	if !almostEqual(resp.Density, 49.295, 0.1) || !almostEqual(resp.Z, 0.8200, 0.0001) {
+		t.Errorf("Wrong properties for N1: density = %f, z = %f", resp.Density, resp.Z)
+	}
+	if len(resp.Warnings) != 0 {
+		t.Errorf("Unexpected warnings: %v", resp.Warnings)
+	}
+
+	// AGA8 рассчитывает только плотность и коэффициент сжимаемости
+	resp, code = calcCAPI(ids, fractions, 5, 250, 1)
+	if code != capiOK {
+		t.Fatalf("Unexpected error code %d", code)
+	}
+	if !math.IsNaN(resp.K) || !math.IsNaN(resp.W) || !math.IsNaN(resp.Mu) {
+		t.Errorf("Expected NaN for properties not calculated by aga8: %+v", resp)
+	}
+	// NaN определяется возможностями метода, а не значением свойства
+	for model, calculated := range []bool{true, false, false, false, true, true} {
+		resp, code = calcCAPI(ids, fractions, 5, 250, int32(model))
+		if code != capiOK {
+			t.Fatalf("Unexpected error code %d for model %s", code, capiModels[model])
+		}
+		if nan := math.IsNaN(resp.K) || math.IsNaN(resp.W) || math.IsNaN(resp.Mu); nan == calculated {
+			t.Errorf("Wrong NaN properties for model %s: %+v", capiModels[model], resp)
+		}
+	}
+
+	errorCases := []struct {
+		name      string
+		ids       []int32
+		fractions []float64
+		p, t      float64
+		model     int32
+		code      int32
+	}{
+		{"unknown component", []int32{0}, []float64{100}, 5, 300, 0, capiComposition},
+		{"duplicate component", []int32{1, 1}, []float64{50, 50}, 5, 300, 0, capiComposition},
+		{"negative fraction", []int32{1, 2}, []float64{101, -1}, 5, 300, 0, capiComposition},
+		{"unknown model", []int32{1}, []float64{100}, 5, 300, 6, capiModel},
+		{"zero pressure", []int32{1}, []float64{100}, 0, 300, 0, capiArgument},
+		{"NaN temperature", []int32{1}, []float64{100}, 5, math.NaN(), 0, capiArgument},
+	}
+	for _, tc := range errorCases {
+		if _, code := calcCAPI(tc.ids, tc.fractions, tc.p, tc.t, tc.model); code != tc.code {
+			t.Errorf("%s: expected code %d, actual %d", tc.name, tc.code, code)
+		}
+	}
+}
@@ -7,14 +7,33 @@ type componentFraction struct {
 
 type context struct {
 	fractions []componentFraction
-	p         float64
-	t         float64
+	// доли компонентов в том виде, в котором они заданы, до корректировки в initFractions
+	inputFractions []componentFraction
+	p              float64
+	t              float64
+	// плотность газа в кг/м^3, задается для обратных задач
+	rho float64
+	// плотность газа при стандартных условиях (20 °С, 0,101325 МПа) в кг/м^3, для методов ГОСТ 30319.2
+	rhoc float64
+	// удельные энтальпия в кДж/кг и энтропия в кДж/(кг*К), задаются для обратных задач
+	h float64
+	s float64
+	// заданы ли энтальпия и энтропия: нулевое значение допустимо, поэтому по нему нельзя судить о задании
+	hSet bool
+	sSet bool
+	// влагосодержание в мг/м^3 при стандартных условиях и температура точки росы по воде в К,
+	// задается одно из двух
+	waterContent  float64
+	waterDewPoint float64
+	// стандартные условия для приведения объема
+	std *standardConditions
 }
 
 // Modifies component fractions to magically improve precision. IDK, just following the standard.
 //
 // This function should be called only once after creating new context
 func (c *context) initFractions() {
+	c.inputFractions = append([]componentFraction(nil), c.fractions...)
 	iHelium, iNitrogen, iHydrogen := -1, -1, -1
 	for i, cf := range c.fractions {
 		if cf.component == &helium {
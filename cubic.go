@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Кубические уравнения состояния Пенга-Робинсона и Соаве-Редлиха-Квонга для оценочных расчетов
+// вне области применимости ГОСТ 30319.3 и для проверки на двухфазность. Оба уравнения записаны
+// в общем виде p = RT/(v-b) - a(T)/((v+εb)(v+σb)) с классическими правилами смешения.
+
+// выбор корня кубического уравнения относительно Z
+type cubicRoot int
+
+const (
+	// корень с наименьшей энергией Гиббса
+	rootGibbs cubicRoot = iota
+	// наибольший корень, газовая фаза
+	rootVapor
+	// наименьший корень, жидкая фаза
+	rootLiquid
+)
+
+var cubicRootByName = map[string]cubicRoot{
+	"gibbs":  rootGibbs,
+	"vapor":  rootVapor,
+	"liquid": rootLiquid,
+}
+
+type cubicEOS struct {
+	name string
+	// константы общего вида уравнения
+	epsilon float64
+	sigma   float64
+	omegaA  float64
+	omegaB  float64
+	// параметр m функции alpha = (1 + m*(1 - sqrt(Tr)))^2 по ацентрическому фактору
+	mFunc func(w float64) float64
+	// коэффициенты функции alpha Матиаса-Копмана для отдельных компонентов:
+	// alpha = (1 + c1*q + c2*q^2 + c3*q^3)^2, q = 1 - sqrt(Tr)
+	mathiasCopeman map[*gasComponent][3]float64
+	// параметры бинарного взаимодействия, ключ - пара компонентов в любом порядке
+	kij  map[[2]*gasComponent]float64
+	root cubicRoot
+}
+
+// параметры бинарного взаимодействия по умолчанию, типичные значения для уравнения Пенга-Робинсона.
+// Уравнение Соаве-Редлиха-Квонга использует эту же таблицу: отдельного набора для него нет.
+// Значения, подобранные для двух уравнений по одним и тем же данным о фазовом равновесии, для пар
+// углеводород - азот и углеводород - диоксид углерода близки, для пар с водой расхождение больше.
+// Для расчетов по SRK, где это важно, значения заменяются файлом флага -kij
+var defaultKij = map[[2]*gasComponent]float64{
+	{&methane, &nitrogen}:       0.036,
+	{&methane, &carbonDioxide}:  0.092,
+	{&ethane, &nitrogen}:        0.05,
+	{&ethane, &carbonDioxide}:   0.13,
+	{&propane, &nitrogen}:       0.085,
+	{&propane, &carbonDioxide}:  0.125,
+	{&iButane, &nitrogen}:       0.1,
+	{&iButane, &carbonDioxide}:  0.12,
+	{&nButane, &nitrogen}:       0.08,
+	{&nButane, &carbonDioxide}:  0.13,
+	{&iPentane, &nitrogen}:      0.095,
+	{&iPentane, &carbonDioxide}: 0.12,
+	{&nPentane, &nitrogen}:      0.1,
+	{&nPentane, &carbonDioxide}: 0.12,
+	{&nHexane, &nitrogen}:       0.15,
+	{&nHexane, &carbonDioxide}:  0.11,
+	{&nitrogen, &carbonDioxide}: -0.017,
+	{&methane, &water}:          0.485,
+	{&ethane, &water}:           0.492,
+	{&propane, &water}:          0.5525,
+	{&iButane, &water}:          0.5,
+	{&nButane, &water}:          0.5,
+	{&iPentane, &water}:         0.5,
+	{&nPentane, &water}:         0.5,
+	{&nHexane, &water}:          0.5,
+	{&nitrogen, &water}:         0.4778,
+	{&carbonDioxide, &water}:    0.1896,
+}
+
+// уравнение Пенга-Робинсона
+func newPengRobinson(kij map[[2]*gasComponent]float64, root cubicRoot) *cubicEOS {
+	return &cubicEOS{
+		name:    "pr",
+		epsilon: 1 - math.Sqrt2,
+		sigma:   1 + math.Sqrt2,
+		omegaA:  0.45724,
+		omegaB:  0.07780,
+		mFunc: func(w float64) float64 {
+			return 0.37464 + 1.54226*w - 0.26992*w*w
+		},
+		// для воды - литературная аппроксимация Матиаса-Копмана давления насыщенного пара воды
+		// для уравнения Пенга-Робинсона, а не функция alpha ISO 18453 (см. water.go)
+		mathiasCopeman: map[*gasComponent][3]float64{
+			&water: {0.9054, -0.2138, 0.2600},
+		},
+		kij:  kij,
+		root: root,
+	}
+}
+
+// уравнение Соаве-Редлиха-Квонга
+func newSoaveRedlichKwong(kij map[[2]*gasComponent]float64, root cubicRoot) *cubicEOS {
+	return &cubicEOS{
+		name:    "srk",
+		epsilon: 0,
+		sigma:   1,
+		omegaA:  0.42748,
+		omegaB:  0.08664,
+		mFunc: func(w float64) float64 {
+			return 0.480 + 1.574*w - 0.176*w*w
+		},
+		kij:  kij,
+		root: root,
+	}
+}
+
+// кубическое уравнение по имени модели: pr или srk
+func newCubicEOS(name string, kij map[[2]*gasComponent]float64, root cubicRoot) (*cubicEOS, error) {
+	switch name {
+	case "pr":
+		return newPengRobinson(kij, root), nil
+	case "srk":
+		return newSoaveRedlichKwong(kij, root), nil
+	}
+	return nil, fmt.Errorf("unknown cubic equation of state: %s", name)
+}
+
+func (e *cubicEOS) Name() string { return e.name }
+
+func (e *cubicEOS) Properties(ctx *context) (*gasProperties, error) {
+	return prepareAndCalc(e, ctx)
+}
+
+// параметры кубического уравнения зависят от температуры, поэтому подготовка сводится к проверке состава
+func (e *cubicEOS) Prepare(ctx *context) (preparedProperties, error) {
+	if err := checkFullComposition(ctx); err != nil {
+		return nil, err
+	}
+	return func(point *context) (*gasProperties, error) {
+		if point.p <= 0 || point.t <= 0 {
+			return nil, fmt.Errorf("pressure and temperature must be positive for model %s", e.name)
+		}
+		m := e.newMixture(point)
+		sol := e.solve(point, m)
+		if err := e.checkRoot(point, sol); err != nil {
+			return nil, err
+		}
+		return e.getProperties(point, m, sol), nil
+	}, nil
+}
+
+func (e *cubicEOS) getKij(ci, cj *gasComponent) float64 {
+	if k, ok := e.kij[[2]*gasComponent{ci, cj}]; ok {
+		return k
+	}
+	return e.kij[[2]*gasComponent{cj, ci}]
+}
+
+// чтение параметров бинарного взаимодействия из файла. Каждая строка содержит два компонента
+// через запятую и значение, например: метан, азот 0,036. Значения из файла дополняют
+// и заменяют значения по умолчанию
+func readKij(path string) (map[[2]*gasComponent]float64, error) {
+	kij := make(map[[2]*gasComponent]float64, len(defaultKij))
+	for pair, k := range defaultKij {
+		kij[pair] = k
+	}
+	if path == "" {
+		return kij, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		name, value, err := parseInputLine(sc.Text())
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			continue
+		}
+		names := strings.Split(name, ",")
+		if len(names) != 2 {
+			return nil, fmt.Errorf("kij line must contain two components separated by comma: %s", name)
+		}
+		ci, ok := componentsByName[strings.TrimSpace(names[0])]
+		if !ok {
+			return nil, fmt.Errorf("unknown component: %s", names[0])
+		}
+		cj, ok := componentsByName[strings.TrimSpace(names[1])]
+		if !ok {
+			return nil, fmt.Errorf("unknown component: %s", names[1])
+		}
+		delete(kij, [2]*gasComponent{cj, ci})
+		kij[[2]*gasComponent{ci, cj}] = value
+	}
+	return kij, sc.Err()
+}
+
+// параметры смеси кубического уравнения при температуре из контекста
+type cubicMixture struct {
+	// параметр притяжения a, Па*м^6/моль^2, и его первая и вторая производные по температуре
+	a   float64
+	da  float64
+	d2a float64
+	// коволюм b, м^3/моль
+	b float64
+	// коволюмы компонентов и суммы x_j*a_ij для расчета летучести
+	bi  []float64
+	xai []float64
+}
+
+func (e *cubicEOS) newMixture(ctx *context) *cubicMixture {
+	n := ctx.length()
+	m := &cubicMixture{bi: make([]float64, n), xai: make([]float64, n)}
+	// sqrt(ac_i), sqrt(alpha_i) и производные sqrt(alpha_i) по температуре
+	ac := make([]float64, n)
+	s := make([]float64, n)
+	ds := make([]float64, n)
+	d2s := make([]float64, n)
+	for i := int32(0); i < n; i++ {
+		c := ctx.component(i)
+		pc := c.pc * math.Pow10(6)
+		ac[i] = math.Sqrt(e.omegaA * R * R * c.tcr * c.tcr / pc)
+		// sqrt(alpha) как многочлен от q = 1 - sqrt(Tr) и производные q по температуре
+		q := 1 - math.Sqrt(ctx.t/c.tcr)
+		dq := -1 / (2 * math.Sqrt(ctx.t*c.tcr))
+		d2q := 1 / (4 * math.Sqrt(c.tcr) * math.Pow(ctx.t, 1.5))
+		mc, ok := e.mathiasCopeman[c]
+		if !ok {
+			mc = [3]float64{e.mFunc(c.acentric), 0, 0}
+		}
+		s[i] = 1 + mc[0]*q + mc[1]*q*q + mc[2]*q*q*q
+		dsdq := mc[0] + 2*mc[1]*q + 3*mc[2]*q*q
+		ds[i] = dsdq * dq
+		d2s[i] = (2*mc[1]+6*mc[2]*q)*dq*dq + dsdq*d2q
+		m.bi[i] = e.omegaB * R * c.tcr / pc
+		m.b += ctx.fraction(i) * m.bi[i]
+	}
+	for i := int32(0); i < n; i++ {
+		for j := int32(0); j < n; j++ {
+			xx := ctx.fraction(i) * ctx.fraction(j)
+			aij := (1 - e.getKij(ctx.component(i), ctx.component(j))) * ac[i] * ac[j]
+			m.xai[i] += ctx.fraction(j) * aij * s[i] * s[j]
+			m.a += xx * aij * s[i] * s[j]
+			m.da += xx * aij * (ds[i]*s[j] + s[i]*ds[j])
+			m.d2a += xx * aij * (d2s[i]*s[j] + 2*ds[i]*ds[j] + s[i]*d2s[j])
+		}
+	}
+	return m
+}
+
+// действительные корни уравнения z^3 + c2*z^2 + c1*z + c0 = 0 в порядке возрастания
+func solveCubic(c2, c1, c0 float64) []float64 {
+	p := c1 - c2*c2/3
+	q := 2*c2*c2*c2/27 - c2*c1/3 + c0
+	shift := -c2 / 3
+	disc := q*q/4 + p*p*p/27
+	if disc > 0 {
+		sq := math.Sqrt(disc)
+		return []float64{math.Cbrt(-q/2+sq) + math.Cbrt(-q/2-sq) + shift}
+	}
+	if p == 0 {
+		return []float64{shift}
+	}
+	r := 2 * math.Sqrt(-p/3)
+	phi := math.Acos(math.Max(-1, math.Min(1, 3*q/(p*r)))) / 3
+	roots := make([]float64, 3)
+	for k := range roots {
+		roots[k] = r*math.Cos(phi-2*math.Pi*float64(k)/3) + shift
+	}
+	sort.Float64s(roots)
+	return roots
+}
+
+// решение кубического уравнения при давлении и температуре из контекста
+type cubicSolution struct {
+	// приведенные параметры A = a*p/(RT)^2 и B = b*p/(RT)
+	aRed float64
+	bRed float64
+	// физические корни Z > B
+	roots []float64
+	// выбранный корень
+	z float64
+}
+
+// безразмерная остаточная энергия Гиббса (G - G0)/RT, она же ln(phi) для чистого вещества
+func (e *cubicEOS) gibbsResidual(z, aRed, bRed float64) float64 {
+	return z - 1 - math.Log(z-bRed) - e.logTerm(z, aRed, bRed)
+}
+
+// слагаемое A/((σ-ε)B) * ln((Z+σB)/(Z+εB)), общее для энергии Гиббса и летучести
+func (e *cubicEOS) logTerm(z, aRed, bRed float64) float64 {
+	return aRed / ((e.sigma - e.epsilon) * bRed) * math.Log((z+e.sigma*bRed)/(z+e.epsilon*bRed))
+}
+
+func (e *cubicEOS) solve(ctx *context, m *cubicMixture) *cubicSolution {
+	rt := R * ctx.t
+	p := ctx.p * math.Pow10(6)
+	s := &cubicSolution{aRed: m.a * p / (rt * rt), bRed: m.b * p / rt}
+	a, b := s.aRed, s.bRed
+	c2 := (e.epsilon+e.sigma-1)*b - 1
+	c1 := a + e.epsilon*e.sigma*b*b - (e.epsilon+e.sigma)*b*(1+b)
+	c0 := -(a*b + e.epsilon*e.sigma*b*b*(1+b))
+	all := solveCubic(c2, c1, c0)
+	for _, z := range all {
+		if z > b {
+			s.roots = append(s.roots, z)
+		}
+	}
+	// при положительных a и b корень Z > B существует всегда: давление убывает от бесконечности
+	// при V -> b до нуля. Без него остаются только ошибки округления при корне вблизи B, тогда
+	// берется наибольший действительный корень; при NaN в исходных данных корней нет совсем
+	if len(s.roots) == 0 {
+		s.roots = all[len(all)-1:]
+	}
+	if len(s.roots) == 0 {
+		s.z = math.NaN()
+		return s
+	}
+	zl, zv := s.roots[0], s.roots[len(s.roots)-1]
+	switch e.root {
+	case rootVapor:
+		s.z = zv
+	case rootLiquid:
+		s.z = zl
+	default:
+		s.z = zv
+		if e.gibbsResidual(zl, a, b) < e.gibbsResidual(zv, a, b) {
+			s.z = zl
+		}
+	}
+	return s
+}
+
+// проверка выбранного корня: расчет свойств по корню Z <= B или NaN дает NaN
+func (e *cubicEOS) checkRoot(ctx *context, s *cubicSolution) error {
+	if math.IsNaN(s.z) || s.z <= s.bRed {
+		return fmt.Errorf("model %s has no physical root at p = %g MPa, t = %g K", e.name, ctx.p, ctx.t)
+	}
+	return nil
+}
+
+// логарифмы коэффициентов летучести компонентов
+func (e *cubicEOS) getLnPhi(ctx *context, m *cubicMixture, s *cubicSolution) []float64 {
+	n := ctx.length()
+	lnPhi := make([]float64, n)
+	logTerm := e.logTerm(s.z, s.aRed, s.bRed)
+	for i := int32(0); i < n; i++ {
+		bRatio := m.bi[i] / m.b
+		lnPhi[i] = bRatio*(s.z-1) - math.Log(s.z-s.bRed) - logTerm*(2*m.xai[i]/m.a-bRatio)
+	}
+	return lnPhi
+}
+
+// летучести компонентов, МПа
+func (e *cubicEOS) getFugacity(ctx *context, m *cubicMixture, s *cubicSolution) []float64 {
+	f := e.getLnPhi(ctx, m, s)
+	for i := range f {
+		f[i] = ctx.fraction(int32(i)) * ctx.p * math.Exp(f[i])
+	}
+	return f
+}
+
+// логарифмы коэффициентов летучести в фазе состава x при температуре t, К, и давлении p, МПа.
+// Состав задается долями компонентов контекста в том же порядке. Для каждой фазы берется корень
+// с наименьшей энергией Гиббса независимо от выбора корня в настройках уравнения
+func (e *cubicEOS) getPhaseLnPhi(ctx *context, x []float64, t, p float64) []float64 {
+	point := *ctx
+	point.t, point.p = t, p
+	point.fractions = make([]componentFraction, len(x))
+	total := 0.0
+	for _, xi := range x {
+		total += xi
+	}
+	for i, xi := range x {
+		point.fractions[i] = componentFraction{ctx.component(int32(i)), xi / total}
+	}
+	phase := *e
+	phase.root = rootGibbs
+	m := phase.newMixture(&point)
+	return phase.getLnPhi(&point, m, phase.solve(&point, m))
+}
+
+// свойства газа по кубическому уравнению, результат в том же виде, что и по ГОСТ 30319.3
+func (e *cubicEOS) getProperties(ctx *context, m *cubicMixture, s *cubicSolution) *gasProperties {
+	t := ctx.t
+	// молярный объем, м^3/моль
+	v := s.z * R * t / (ctx.p * math.Pow10(6))
+	ve, vs := v+e.epsilon*m.b, v+e.sigma*m.b
+	// молярная масса, кг/моль
+	mm := getMm(ctx) * math.Pow10(-3)
+	dpdt := R/(v-m.b) - m.da/(ve*vs)
+	dpdv := -R*t/((v-m.b)*(v-m.b)) + m.a*(ve+vs)/(ve*ve*vs*vs)
+	// остаточная изохорная теплоемкость T*a''*∫dv/((v+εb)(v+σb))
+	cvr := t * m.d2a * math.Log(vs/ve) / ((e.sigma - e.epsilon) * m.b)
+	cv := R*(getCp0r(ctx)-1) + cvr
+	cp := cv - t*dpdt*dpdt/dpdv
+	density := mm / v
+	pMolPc := getPMolPc(ctx)
+	tpc := getTpc(ctx, pMolPc)
+	deltaU := getDeltaU(getPhi(ctx), getOmegaM(getPMol(density, getMm(ctx)), pMolPc), getTauM(ctx, tpc))
+	return &gasProperties{
+		z:        s.z,
+		density:  density,
+		k:        -v / (ctx.p * math.Pow10(6)) * cp / cv * dpdv,
+		w:        math.Sqrt(-v * v / mm * cp / cv * dpdv),
+		mu:       getMu(ctx, getMu0(ctx, getMu0Comp(ctx)), getMm(ctx), getPpc(ctx, pMolPc, tpc), tpc, deltaU),
+		fugacity: e.getFugacity(ctx, m, s),
+	}
+}
+
+// расчет свойств по кубическому уравнению состояния
+func (e *cubicEOS) calc(ctx *context, out *output) error {
+	if ctx.p <= 0 || ctx.t <= 0 {
+		return fmt.Errorf("pressure and temperature must be positive for model %s", e.name)
+	}
+	if err := checkFullComposition(ctx); err != nil {
+		return err
+	}
+	m := e.newMixture(ctx)
+	s := e.solve(ctx, m)
+	if err := e.checkRoot(ctx, s); err != nil {
+		return err
+	}
+	gp := e.getProperties(ctx, m, s)
+	if out.level >= normal {
+		out.writeCubicSolution(s)
+	}
+	out.writeP(gp.density)
+	out.writeZ(gp.z)
+	if out.level >= normal {
+		out.writeFugacity(ctx, gp.fugacity)
+		// плотность и z уже выведены, поэтому ошибка двухфазного расчета - предупреждение, а не отказ
+		if fr, err := e.flash(ctx); err != nil {
+			out.printf("Предупреждение: фазовое состояние не определено: %v\n", err)
+		} else {
+			out.writeFlash(ctx, fr)
+		}
+	}
+	return nil
+}
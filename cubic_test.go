@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSolveCubic(t *testing.T) {
+	// (z - 0.1)(z - 0.5)(z - 0.9)
+	roots := solveCubic(-1.5, 0.59, -0.045)
+	expected := []float64{0.1, 0.5, 0.9}
+	if len(roots) != len(expected) {
+		t.Fatalf("Wrong number of roots; actual = %v", roots)
+	}
+	for i := range roots {
+		if !almostEqual(roots[i], expected[i], 1e-9) {
+			t.Errorf("Wrong root %d; actual = %f, expected = %f", i, roots[i], expected[i])
+		}
+	}
+}
+
+func TestCubicAgainstGost(t *testing.T) {
+	ctx := n1Context()
+	m := newMixture(ctx)
+	for _, name := range []string{"pr", "srk"} {
+		eos, _ := newCubicEOS(name, defaultKij, rootGibbs)
+		for _, tc := range n1TestCases {
+			// кубические уравнения - оценочные, при высоких давлениях отклонение от ГОСТ превышает несколько процентов
+			if tc.p > 10 {
+				continue
+			}
+			ctx.p, ctx.t = tc.p, tc.t
+			gp := mustProperties(ctx, m)
+			cm := eos.newMixture(ctx)
+			cp := eos.getProperties(ctx, cm, eos.solve(ctx, cm))
+			if !almostEqual(cp.z, gp.z, gp.z*0.03) {
+				t.Errorf("%s: z deviates at p = %f, t = %f; actual = %f, gost = %f", name, tc.p, tc.t, cp.z, gp.z)
+			}
+			if !almostEqual(cp.w, gp.w, gp.w*0.05) {
+				t.Errorf("%s: w deviates at p = %f, t = %f; actual = %f, gost = %f", name, tc.p, tc.t, cp.w, gp.w)
+			}
+			if !almostEqual(cp.k, gp.k, gp.k*0.05) {
+				t.Errorf("%s: k deviates at p = %f, t = %f; actual = %f, gost = %f", name, tc.p, tc.t, cp.k, gp.k)
+			}
+		}
+	}
+}
+
+// ln(phi_i) должен совпадать с производной n*(G - G0)/RT по количеству молей компонента
+func TestCubicFugacity(t *testing.T) {
+	eos := newPengRobinson(defaultKij, rootGibbs)
+	ctx := n1Context()
+	ctx.p, ctx.t = 8, 280
+	cm := eos.newMixture(ctx)
+	f := eos.getProperties(ctx, cm, eos.solve(ctx, cm)).fugacity
+	moles := make([]float64, ctx.length())
+	for i := range moles {
+		moles[i] = ctx.fraction(int32(i))
+	}
+	// n*(G - G0)/RT при заданных количествах молей
+	nG := func(moles []float64) float64 {
+		point := *ctx
+		point.fractions = make([]componentFraction, len(moles))
+		total := 0.0
+		for _, n := range moles {
+			total += n
+		}
+		for i, n := range moles {
+			point.fractions[i] = componentFraction{ctx.component(int32(i)), n / total}
+		}
+		m := eos.newMixture(&point)
+		s := eos.solve(&point, m)
+		return total * eos.gibbsResidual(s.z, s.aRed, s.bRed)
+	}
+	const dn = 1e-6
+	for i := range moles {
+		plus := append([]float64(nil), moles...)
+		minus := append([]float64(nil), moles...)
+		plus[i] += dn
+		minus[i] -= dn
+		lnPhi := (nG(plus) - nG(minus)) / (2 * dn)
+		expected := moles[i] * ctx.p * math.Exp(lnPhi)
+		if !almostEqual(f[i], expected, expected*1e-5) {
+			t.Errorf("Wrong fugacity of %s; actual = %g, expected = %g", ctx.component(int32(i)).name, f[i], expected)
+		}
+	}
+}
+
+func TestCubicRootSelection(t *testing.T) {
+	ctx := &context{fractions: []componentFraction{{&propane, 1}}, t: 300}
+	eos := newPengRobinson(defaultKij, rootGibbs)
+	// давление насыщенных паров пропана при 300 К около 1 МПа
+	ctx.p = 0.5
+	m := eos.newMixture(ctx)
+	if s := eos.solve(ctx, m); s.z < 0.5 {
+		t.Errorf("Expected vapor root for propane at 0.5 MPa; actual z = %f", s.z)
+	}
+	ctx.p = 1.2
+	if s := eos.solve(ctx, m); s.z > 0.2 {
+		t.Errorf("Expected liquid root for propane at 1.2 MPa; actual z = %f", s.z)
+	}
+	eos.root = rootVapor
+	if s := eos.solve(ctx, m); len(s.roots) != 3 || s.z != s.roots[2] {
+		t.Errorf("Expected vapor root to be selected; roots = %v, z = %f", s.roots, s.z)
+	}
+}
+
+func TestCubicWithoutPhysicalRoot(t *testing.T) {
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, math.NaN()
+	for _, eos := range []*cubicEOS{newPengRobinson(defaultKij, rootGibbs), newSoaveRedlichKwong(defaultKij, rootGibbs)} {
+		if _, err := eos.Properties(ctx); err == nil {
+			t.Errorf("%s: expected error without a physical root", eos.name)
+		}
+	}
+}
+
+// неполный состав отклоняется и подробным расчетом, и расчетом свойств, без вывода результата
+func TestCubicRequiresFullComposition(t *testing.T) {
+	ctx := fractionsContext(n1Fractions[:3])
+	ctx.p, ctx.t = 5, 283.15
+	for _, eos := range []*cubicEOS{newPengRobinson(defaultKij, rootGibbs), newSoaveRedlichKwong(defaultKij, rootGibbs)} {
+		var buf bytes.Buffer
+		var compErr *compositionError
+		if err := eos.calc(ctx, newStreamOutput(&buf, normal)); !errors.As(err, &compErr) {
+			t.Errorf("%s: expected composition error from calc, got %v", eos.name, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: unexpected output for incomplete composition:\n%s", eos.name, buf.String())
+		}
+		if _, err := eos.Properties(ctx); !errors.As(err, &compErr) {
+			t.Errorf("%s: expected composition error from Properties, got %v", eos.name, err)
+		}
+	}
+}
+
+func TestReadKij(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kij.txt")
+	if err := os.WriteFile(path, []byte("Азот, метан 0,05\n\nэтан, пропан 0.001\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	kij, err := readKij(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eos := newPengRobinson(kij, rootGibbs)
+	if k := eos.getKij(&methane, &nitrogen); k != 0.05 {
+		t.Errorf("Wrong methane-nitrogen kij; actual = %f", k)
+	}
+	if k := eos.getKij(&propane, &ethane); k != 0.001 {
+		t.Errorf("Wrong ethane-propane kij; actual = %f", k)
+	}
+	if k := eos.getKij(&methane, &carbonDioxide); k != defaultKij[[2]*gasComponent{&methane, &carbonDioxide}] {
+		t.Errorf("Default kij must be kept; actual = %f", k)
+	}
+}
@@ -0,0 +1,669 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Двухфазный расчет и построение фазовой диаграммы (кривых точек росы и кипения) по кубическому
+// уравнению состояния. Диаграмма строится методом продолжения Михельсена: неизвестные - логарифмы
+// констант равновесия, температуры и давления, исходный состав принадлежит насыщенной фазе,
+// определяющая переменная выбирается по наибольшей чувствительности вдоль кривой.
+
+const (
+	// давление начала и окончания построения диаграммы, МПа
+	envelopeMinPressure = 0.1
+	// наибольшее число точек диаграммы
+	envelopeMaxPoints = 1000
+	// наибольший шаг по определяющей переменной
+	envelopeMaxStep = 0.1
+	// предельное число итераций двухфазного расчета методом последовательных подстановок
+	maxFlashIterations = 500
+)
+
+// константа равновесия по корреляции Вильсона, t - температура в К, p - давление в МПа
+func (c *gasComponent) wilsonK(t, p float64) float64 {
+	return c.pc / p * math.Exp(5.373*(1+c.acentric)*(1-c.tcr/t))
+}
+
+// доли компонентов контекста
+func getComposition(ctx *context) []float64 {
+	z := make([]float64, ctx.length())
+	for i := range z {
+		z[i] = ctx.fraction(int32(i))
+	}
+	return z
+}
+
+// решение уравнения Рашфорда-Райса относительно мольной доли пара. Если корня в (0, 1) нет,
+// возвращается 0 для жидкости или 1 для пара и ok = false
+func rachfordRice(z, k []float64) (beta float64, ok bool) {
+	g := func(beta float64) float64 {
+		s := 0.0
+		for i := range z {
+			s += z[i] * (k[i] - 1) / (1 + beta*(k[i]-1))
+		}
+		return s
+	}
+	if g(0) <= 0 {
+		return 0, false
+	}
+	if g(1) >= 0 {
+		return 1, false
+	}
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 100 && hi-lo > 1e-15; i++ {
+		mid := (lo + hi) / 2
+		if g(mid) > 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, true
+}
+
+// результат двухфазного расчета
+type flashResult struct {
+	// мольная доля паровой фазы, для однофазного состояния 0 (жидкость) или 1 (газ)
+	beta float64
+	// составы жидкой и паровой фаз, для однофазного состояния совпадают с исходным
+	x []float64
+	y []float64
+}
+
+func (r *flashResult) twoPhase() bool {
+	return r.beta > 0 && r.beta < 1
+}
+
+// анализ устойчивости однофазного состояния по критерию касательной плоскости Михельсена.
+// Для паро- и жидкоподобной пробных фаз с начальными приближениями по Вильсону методом последовательных
+// подстановок ищется стационарная точка функции tm(W) = 1 + sum W_i (ln W_i + ln phi_i(W) - d_i - 1),
+// d_i = ln z_i + ln phi_i(z). Если в стационарной точке sum W_i > 1, то tm < 0 и исходная фаза неустойчива.
+// Для неустойчивого состояния возвращаются константы равновесия по пробной фазе с наибольшей sum W_i
+func (e *cubicEOS) stability(ctx *context, z []float64) (k []float64, unstable bool) {
+	n := len(z)
+	lnPhiZ := e.getPhaseLnPhi(ctx, z, ctx.t, ctx.p)
+	d := make([]float64, n)
+	for i := range z {
+		if z[i] > 0 {
+			d[i] = math.Log(z[i]) + lnPhiZ[i]
+		}
+	}
+	best := 1.0
+	for _, vapor := range []bool{true, false} {
+		w := make([]float64, n)
+		for i := range z {
+			kw := ctx.component(int32(i)).wilsonK(ctx.t, ctx.p)
+			if vapor {
+				w[i] = z[i] * kw
+			} else {
+				w[i] = z[i] / kw
+			}
+		}
+		for iter := 0; iter < maxFlashIterations; iter++ {
+			lnPhiW := e.getPhaseLnPhi(ctx, w, ctx.t, ctx.p)
+			change, trivial := 0.0, 0.0
+			for i := range w {
+				if z[i] == 0 {
+					continue
+				}
+				lnW := d[i] - lnPhiW[i]
+				change += math.Pow(lnW-math.Log(w[i]), 2)
+				trivial += math.Pow(lnW-math.Log(z[i]), 2)
+				w[i] = math.Exp(lnW)
+			}
+			// пробная фаза совпала с исходной - стационарная точка тривиальная
+			if trivial < 1e-8 || change < 1e-14 {
+				break
+			}
+		}
+		total := 0.0
+		for _, wi := range w {
+			total += wi
+		}
+		if math.IsNaN(total) || total <= best+1e-8 {
+			continue
+		}
+		best, unstable = total, true
+		k = make([]float64, n)
+		for i := range z {
+			switch {
+			case z[i] == 0:
+				k[i] = 1
+			case vapor:
+				k[i] = w[i] / z[i]
+			default:
+				k[i] = z[i] / w[i]
+			}
+		}
+	}
+	return k, unstable
+}
+
+// двухфазный расчет при давлении и температуре из контекста методом последовательных подстановок
+// с начальным приближением констант равновесия по Вильсону. Если по константам Вильсона состояние
+// однофазное, оно проверяется анализом устойчивости: константы Вильсона вблизи границы фаз
+// неточны, и неустойчивое однофазное состояние рассчитывается дальше как двухфазное
+func (e *cubicEOS) flash(ctx *context) (*flashResult, error) {
+	z := getComposition(ctx)
+	n := len(z)
+	k := make([]float64, n)
+	for i := range k {
+		k[i] = ctx.component(int32(i)).wilsonK(ctx.t, ctx.p)
+	}
+	x, y := make([]float64, n), make([]float64, n)
+	stabilityChecked := false
+	for iter := 0; iter < maxFlashIterations; iter++ {
+		beta, ok := rachfordRice(z, k)
+		if !ok && !stabilityChecked {
+			stabilityChecked = true
+			if stableK, unstable := e.stability(ctx, z); unstable {
+				k = stableK
+				beta, ok = rachfordRice(z, k)
+			}
+		}
+		if !ok {
+			return &flashResult{beta: beta, x: z, y: z}, nil
+		}
+		for i := range z {
+			x[i] = z[i] / (1 + beta*(k[i]-1))
+			y[i] = k[i] * x[i]
+		}
+		lnPhiL := e.getPhaseLnPhi(ctx, x, ctx.t, ctx.p)
+		lnPhiV := e.getPhaseLnPhi(ctx, y, ctx.t, ctx.p)
+		change, trivial := 0.0, 0.0
+		for i := range k {
+			lnK := lnPhiL[i] - lnPhiV[i]
+			change += math.Pow(lnK-math.Log(k[i]), 2)
+			trivial += lnK * lnK
+			k[i] = math.Exp(lnK)
+		}
+		// фазы с одинаковым составом - однофазное состояние
+		if trivial < 1e-8 {
+			return &flashResult{beta: 1, x: z, y: z}, nil
+		}
+		if change < 1e-14 {
+			return &flashResult{beta: beta, x: x, y: y}, nil
+		}
+	}
+	return nil, fmt.Errorf("flash iterations did not converge in %d steps", maxFlashIterations)
+}
+
+// решение системы линейных уравнений a*x = b методом Гаусса с выбором главного элемента
+func solveLinear(a [][]float64, b []float64) ([]float64, error) {
+	n := len(b)
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = append(append(make([]float64, 0, n+1), a[i]...), b[i])
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if m[pivot][col] == 0 {
+			return nil, errors.New("singular matrix")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for row := col + 1; row < n; row++ {
+			f := m[row][col] / m[col][col]
+			for j := col; j <= n; j++ {
+				m[row][j] -= f * m[col][j]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		s := m[row][n]
+		for j := row + 1; j < n; j++ {
+			s -= m[row][j] * x[j]
+		}
+		x[row] = s / m[row][row]
+	}
+	return x, nil
+}
+
+// невязки уравнений насыщения. X = (ln K_1, ..., ln K_n, ln T, ln P), насыщенная фаза имеет
+// исходный состав z, состав зарождающейся фазы z_i/K_i
+func (e *cubicEOS) saturationResiduals(ctx *context, z, X []float64) []float64 {
+	n := len(z)
+	t, p := math.Exp(X[n]), math.Exp(X[n+1])
+	x := make([]float64, n)
+	total := 0.0
+	for i := range z {
+		x[i] = z[i] / math.Exp(X[i])
+		total += x[i]
+	}
+	lnPhiZ := e.getPhaseLnPhi(ctx, z, t, p)
+	lnPhiX := e.getPhaseLnPhi(ctx, x, t, p)
+	f := make([]float64, n+1)
+	for i := range z {
+		f[i] = X[i] + lnPhiZ[i] - lnPhiX[i]
+	}
+	f[n] = total - 1
+	return f
+}
+
+// матрица Якоби уравнений насыщения с уравнением X[spec] = S, производные численные
+func (e *cubicEOS) saturationJacobian(ctx *context, z, X []float64, f []float64, spec int) [][]float64 {
+	size := len(X)
+	jac := make([][]float64, size)
+	for i := range jac {
+		jac[i] = make([]float64, size)
+	}
+	shifted := append([]float64(nil), X...)
+	for j := range X {
+		h := 1e-7 * math.Max(1, math.Abs(X[j]))
+		shifted[j] = X[j] + h
+		fh := e.saturationResiduals(ctx, z, shifted)
+		shifted[j] = X[j]
+		for i := range fh {
+			jac[i][j] = (fh[i] - f[i]) / h
+		}
+	}
+	jac[size-1][spec] = 1
+	return jac
+}
+
+// расчет точки насыщения методом Ньютона при заданном значении переменной X[spec],
+// X содержит начальное приближение и заменяется решением
+func (e *cubicEOS) solveSaturation(ctx *context, z, X []float64, spec int) (int, error) {
+	n := len(z)
+	for iter := 1; iter <= 30; iter++ {
+		f := e.saturationResiduals(ctx, z, X)
+		jac := e.saturationJacobian(ctx, z, X, f, spec)
+		dx, err := solveLinear(jac, append(negate(f), 0))
+		if err != nil {
+			return iter, err
+		}
+		// ограничение шага защищает от ухода в область отрицательных корней
+		maxStep := 0.0
+		for _, d := range dx {
+			maxStep = math.Max(maxStep, math.Abs(d))
+		}
+		scale := 1.0
+		if maxStep > 1 {
+			scale = 1 / maxStep
+		}
+		trivial := true
+		for i := range X {
+			X[i] += scale * dx[i]
+			if math.IsNaN(X[i]) {
+				return iter, errors.New("saturation iterations diverged")
+			}
+			if i < n && math.Abs(X[i]) > 1e-5 {
+				trivial = false
+			}
+		}
+		if trivial {
+			return iter, errors.New("saturation iterations converged to trivial solution")
+		}
+		if maxStep < 1e-10 {
+			return iter, nil
+		}
+	}
+	return 30, errors.New("saturation iterations did not converge")
+}
+
+func negate(v []float64) []float64 {
+	r := make([]float64, len(v))
+	for i := range v {
+		r[i] = -v[i]
+	}
+	return r
+}
+
+// производные неизвестных по определяющей переменной вдоль кривой насыщения
+func (e *cubicEOS) saturationTangent(ctx *context, z, X []float64, spec int) ([]float64, error) {
+	f := e.saturationResiduals(ctx, z, X)
+	jac := e.saturationJacobian(ctx, z, X, f, spec)
+	rhs := make([]float64, len(X))
+	rhs[len(X)-1] = 1
+	return solveLinear(jac, rhs)
+}
+
+// точка фазовой диаграммы
+type envelopePoint struct {
+	// температура, К
+	t float64
+	// давление, МПа
+	p float64
+	// true - точка росы, false - точка кипения
+	dew bool
+	// неизвестные уравнений насыщения в точке
+	X []float64
+}
+
+type phaseEnvelope struct {
+	points []envelopePoint
+	// критическая точка, если диаграмма прошла через нее
+	critical *envelopePoint
+	// точки наибольших температуры и давления на диаграмме
+	cricondentherm envelopePoint
+	cricondenbar   envelopePoint
+}
+
+// температура точки росы по константам Вильсона, начальное приближение для построения диаграммы
+func wilsonDewTemperature(ctx *context, z []float64, p float64) float64 {
+	lo, hi := 10.0, 2000.0
+	for i := 0; i < 100; i++ {
+		t := math.Sqrt(lo * hi)
+		s := 0.0
+		for j := range z {
+			s += z[j] / ctx.component(int32(j)).wilsonK(t, p)
+		}
+		if s > 1 {
+			lo = t
+		} else {
+			hi = t
+		}
+	}
+	return math.Sqrt(lo * hi)
+}
+
+// построение фазовой диаграммы от точки росы при минимальном давлении через критическую точку
+// до точки кипения при минимальном давлении
+func (e *cubicEOS) getPhaseEnvelope(ctx *context) (*phaseEnvelope, error) {
+	z := getComposition(ctx)
+	n := len(z)
+	heavy, present := -1, 0
+	for i := range z {
+		if z[i] <= 0 {
+			continue
+		}
+		present++
+		if heavy == -1 || ctx.component(int32(i)).tcr > ctx.component(int32(heavy)).tcr {
+			heavy = i
+		}
+	}
+	if present < 2 {
+		return nil, errors.New("phase envelope requires at least two components")
+	}
+	t0 := wilsonDewTemperature(ctx, z, envelopeMinPressure)
+	X := make([]float64, n+2)
+	for i := range z {
+		X[i] = math.Log(ctx.component(int32(i)).wilsonK(t0, envelopeMinPressure))
+	}
+	X[n], X[n+1] = math.Log(t0), math.Log(envelopeMinPressure)
+	spec := n + 1
+	if _, err := e.solveSaturation(ctx, z, X, spec); err != nil {
+		return nil, fmt.Errorf("initial dew point at %g MPa: %w", envelopeMinPressure, err)
+	}
+	env := &phaseEnvelope{}
+	add := func(X []float64) {
+		env.points = append(env.points, envelopePoint{
+			t: math.Exp(X[n]), p: math.Exp(X[n+1]), dew: X[heavy] < 0, X: append([]float64(nil), X...),
+		})
+	}
+	add(X)
+	tangent, err := e.saturationTangent(ctx, z, X, spec)
+	if err != nil {
+		return nil, err
+	}
+	step := envelopeMaxStep / 4
+	for len(env.points) < envelopeMaxPoints {
+		// определяющая переменная - с наибольшей чувствительностью, касательная нормируется по ней
+		spec = 0
+		for i := range tangent {
+			if math.Abs(tangent[i]) > math.Abs(tangent[spec]) {
+				spec = i
+			}
+		}
+		scale := math.Abs(tangent[spec])
+		for i := range tangent {
+			tangent[i] /= scale
+		}
+		h := step
+		// вблизи критической точки шаг выбирается так, чтобы перейти через нулевые ln K,
+		// иначе метод Ньютона сходится к тривиальному решению
+		if spec < n && X[spec]*tangent[spec] < 0 && math.Abs(X[spec]) < 2*step {
+			h = 2 * math.Abs(X[spec])
+		}
+		next := make([]float64, len(X))
+		for i := range X {
+			next[i] = X[i] + h*tangent[i]
+		}
+		iters, err := e.solveSaturation(ctx, z, next, spec)
+		if err != nil {
+			step /= 2
+			if step < 1e-6 {
+				return env.finish(), fmt.Errorf("phase envelope tracing stopped at t = %g K, p = %g MPa: %w",
+					math.Exp(X[n]), math.Exp(X[n+1]), err)
+			}
+			continue
+		}
+		newTangent, err := e.saturationTangent(ctx, z, next, spec)
+		if err != nil {
+			return env.finish(), err
+		}
+		dot := 0.0
+		for i := range tangent {
+			dot += tangent[i] * newTangent[i]
+		}
+		if dot < 0 {
+			newTangent = negate(newTangent)
+		}
+		if (X[heavy] < 0) != (next[heavy] < 0) {
+			// критическая точка - линейная интерполяция по ln K самого тяжелого компонента
+			w := X[heavy] / (X[heavy] - next[heavy])
+			env.critical = &envelopePoint{
+				t: math.Exp(X[n] + w*(next[n]-X[n])),
+				p: math.Exp(X[n+1] + w*(next[n+1]-X[n+1])),
+			}
+		}
+		X, tangent = next, newTangent
+		add(X)
+		if iters <= 3 {
+			step = math.Min(step*1.5, envelopeMaxStep)
+		} else if iters > 6 {
+			step /= 2
+		}
+		if math.Exp(X[n+1]) < envelopeMinPressure || math.Exp(X[n]) < 20 {
+			break
+		}
+	}
+	return env.finish(), nil
+}
+
+// крикондентерм и крикондебар по построенным точкам. Вызывается и для диаграммы, построение
+// которой прервалось: тогда это наибольшие температура и давление на построенном участке
+func (env *phaseEnvelope) finish() *phaseEnvelope {
+	if len(env.points) > 0 {
+		env.cricondentherm = env.getCricondentherm()
+		env.cricondenbar = env.getCricondenbar()
+	}
+	return env
+}
+
+// вершина параболы, проходящей через три точки; ok = false, если парабола не имеет максимума
+func parabolaVertex(x0, y0, x1, y1, x2, y2 float64) (x, y float64, ok bool) {
+	denom := (x0 - x1) * (x0 - x2) * (x1 - x2)
+	if denom == 0 {
+		return 0, 0, false
+	}
+	a := (x2*(y1-y0) + x1*(y0-y2) + x0*(y2-y1)) / denom
+	b := (x2*x2*(y0-y1) + x1*x1*(y2-y0) + x0*x0*(y1-y2)) / denom
+	c := (x1*x2*(x1-x2)*y0 + x2*x0*(x2-x0)*y1 + x0*x1*(x0-x1)*y2) / denom
+	if a >= 0 {
+		return 0, 0, false
+	}
+	return -b / (2 * a), c - b*b/(4*a), true
+}
+
+// индекс точки диаграммы с наибольшим значением величины
+func (env *phaseEnvelope) argmax(value func(p envelopePoint) float64) int {
+	best := 0
+	for i, p := range env.points {
+		if value(p) > value(env.points[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// крикондентерм с уточнением по параболе T(ln p) через соседние точки
+func (env *phaseEnvelope) getCricondentherm() envelopePoint {
+	i := env.argmax(func(p envelopePoint) float64 { return p.t })
+	result := envelopePoint{t: env.points[i].t, p: env.points[i].p, dew: env.points[i].dew}
+	if i > 0 && i < len(env.points)-1 {
+		a, b, c := env.points[i-1], env.points[i], env.points[i+1]
+		if lnP, t, ok := parabolaVertex(math.Log(a.p), a.t, math.Log(b.p), b.t, math.Log(c.p), c.t); ok {
+			result.t, result.p = t, math.Exp(lnP)
+		}
+	}
+	return result
+}
+
+// крикондебар с уточнением по параболе p(ln T) через соседние точки
+func (env *phaseEnvelope) getCricondenbar() envelopePoint {
+	i := env.argmax(func(p envelopePoint) float64 { return p.p })
+	result := envelopePoint{t: env.points[i].t, p: env.points[i].p, dew: env.points[i].dew}
+	if i > 0 && i < len(env.points)-1 {
+		a, b, c := env.points[i-1], env.points[i], env.points[i+1]
+		if lnT, p, ok := parabolaVertex(math.Log(a.t), a.p, math.Log(b.t), b.p, math.Log(c.t), c.p); ok {
+			result.t, result.p = math.Exp(lnT), p
+		}
+	}
+	return result
+}
+
+// точка росы при давлении p, МПа. Из точек диаграммы выбирается участок ветви точек росы
+// с наибольшей температурой, на нем уточняется решение
+func (e *cubicEOS) getDewPoint(ctx *context, env *phaseEnvelope, p float64) (float64, error) {
+	z := getComposition(ctx)
+	n := len(z)
+	var start []float64
+	for i := 1; i < len(env.points); i++ {
+		a, b := env.points[i-1], env.points[i]
+		if !a.dew || !b.dew || (a.p-p)*(b.p-p) > 0 {
+			continue
+		}
+		w := (math.Log(p) - a.X[n+1]) / (b.X[n+1] - a.X[n+1])
+		X := make([]float64, n+2)
+		for j := range X {
+			X[j] = a.X[j] + w*(b.X[j]-a.X[j])
+		}
+		if start == nil || X[n] > start[n] {
+			start = X
+		}
+	}
+	if start == nil {
+		return 0, fmt.Errorf("no dew point at %g MPa, pressure is above cricondenbar or outside traced envelope", p)
+	}
+	start[n+1] = math.Log(p)
+	if _, err := e.solveSaturation(ctx, z, start, n+1); err != nil {
+		return 0, err
+	}
+	return math.Exp(start[n]), nil
+}
+
+// вывод диаграммы в CSV. Если построение прервано (incomplete не nil), первой строкой выводится
+// комментарий с причиной: кривые и экстремумы относятся только к построенной части диаграммы
+func writeEnvelopeCSV(w io.Writer, env *phaseEnvelope, contractPressure, dewPoint float64, incomplete error) error {
+	if incomplete != nil {
+		if _, err := fmt.Fprintf(w, "# incomplete envelope: %v\n", incomplete); err != nil {
+			return err
+		}
+	}
+	cw := csv.NewWriter(w)
+	rows := [][]string{{"ветвь", "t, °С", "p, МПа"}}
+	for _, p := range env.points {
+		branch := "кипения"
+		if p.dew {
+			branch = "росы"
+		}
+		rows = append(rows, []string{branch, formatTableValue(p.t - 273.15), formatTableValue(p.p)})
+	}
+	rows = append(rows, nil, []string{"точка", "t, °С", "p, МПа"})
+	if env.critical != nil {
+		rows = append(rows, []string{"критическая", formatTableValue(env.critical.t - 273.15), formatTableValue(env.critical.p)})
+	}
+	rows = append(rows,
+		[]string{"крикондентерм", formatTableValue(env.cricondentherm.t - 273.15), formatTableValue(env.cricondentherm.p)},
+		[]string{"крикондебар", formatTableValue(env.cricondenbar.t - 273.15), formatTableValue(env.cricondenbar.p)},
+	)
+	if contractPressure > 0 {
+		rows = append(rows, []string{"точка росы", formatTableValue(dewPoint - 273.15), formatTableValue(contractPressure)})
+	}
+	for _, row := range rows {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// подкоманда envelope - фазовая диаграмма, крикондентерм и точка росы по углеводородам
+func runEnvelope(args []string) {
+	fs := flag.NewFlagSet("envelope", flag.ExitOnError)
+	inputPath := fs.String("i", "", "путь к файлу с составом газа")
+	outputPath := fs.String("o", "", "путь к файлу для вывода. Необязательно, по умолчанию используется стандартный поток вывода")
+	modelName := fs.String("model", "pr", "кубическое уравнение состояния: pr или srk")
+	kijPath := fs.String("kij", "", "путь к файлу с параметрами бинарного взаимодействия kij")
+	contractPressure := fs.Float64("p", 0, "давление в МПа, при котором рассчитывается точка росы. Необязательно, по умолчанию давление из исходного файла")
+	fs.Parse(args)
+	if *inputPath == "" {
+		fmt.Fprintln(os.Stderr, "missing input path")
+		fs.Usage()
+		os.Exit(1)
+	}
+	kij, err := readKij(*kijPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	eos, err := newCubicEOS(*modelName, kij, rootGibbs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ctx, err := readInput(*inputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// прерванная диаграмма выводится с комментарием, но команда завершается ошибкой
+	env, traceErr := eos.getPhaseEnvelope(ctx)
+	if traceErr != nil {
+		fmt.Fprintln(os.Stderr, traceErr)
+		if env == nil || len(env.points) == 0 {
+			os.Exit(1)
+		}
+	}
+	p := *contractPressure
+	if p == 0 {
+		p = ctx.p
+	}
+	var dewPoint float64
+	if p > 0 {
+		if dewPoint, err = eos.getDewPoint(ctx, env, p); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	out, err := newOutput(*outputPath, normal)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := writeEnvelopeCSV(out.w, env, p, dewPoint, traceErr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := out.close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if traceErr != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRachfordRice(t *testing.T) {
+	z := []float64{0.5, 0.5}
+	// при K = (2, 0.5) доля пара равна 1/2
+	if beta, ok := rachfordRice(z, []float64{2, 0.5}); !ok || !almostEqual(beta, 0.5, 1e-12) {
+		t.Errorf("Wrong vapor fraction; actual = %f, ok = %t", beta, ok)
+	}
+	if beta, ok := rachfordRice(z, []float64{1.5, 1.2}); ok || beta != 1 {
+		t.Errorf("Expected single vapor phase; actual = %f, ok = %t", beta, ok)
+	}
+}
+
+func TestPhaseEnvelope(t *testing.T) {
+	ctx := n1Context()
+	eos := newPengRobinson(defaultKij, rootGibbs)
+	env, err := eos.getPhaseEnvelope(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.critical == nil {
+		t.Fatal("Phase envelope must pass through critical point")
+	}
+	last := env.points[len(env.points)-1]
+	if !env.points[0].dew || last.dew || last.p > envelopeMinPressure {
+		t.Errorf("Envelope must go from dew curve to bubble curve at low pressure; last point t = %f, p = %f", last.t, last.p)
+	}
+	for _, p := range env.points {
+		if p.t > env.cricondentherm.t+1e-3 {
+			t.Errorf("Point t = %f above cricondentherm %f", p.t, env.cricondentherm.t)
+		}
+		if p.p > env.cricondenbar.p+1e-3 {
+			t.Errorf("Point p = %f above cricondenbar %f", p.p, env.cricondenbar.p)
+		}
+	}
+}
+
+// при температуре чуть ниже точки росы газ двухфазный с малой долей жидкости, чуть выше - однофазный
+func TestDewPointMatchesFlash(t *testing.T) {
+	ctx := n1Context()
+	eos := newPengRobinson(defaultKij, rootGibbs)
+	env, err := eos.getPhaseEnvelope(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []float64{1, 5} {
+		dew, err := eos.getDewPoint(ctx, env, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx.p, ctx.t = p, dew-0.5
+		below, err := eos.flash(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !below.twoPhase() || below.beta < 0.99 {
+			t.Errorf("Expected small liquid fraction below dew point %f at %f MPa; beta = %f", dew, p, below.beta)
+		}
+		ctx.t = dew + 0.5
+		above, err := eos.flash(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if above.twoPhase() {
+			t.Errorf("Expected single phase above dew point %f at %f MPa; beta = %f", dew, p, above.beta)
+		}
+	}
+	if _, err := eos.getDewPoint(ctx, env, env.cricondenbar.p*1.1); err == nil {
+		t.Error("Expected error above cricondenbar")
+	}
+}
+
+func TestSolveLinear(t *testing.T) {
+	x, err := solveLinear([][]float64{{0, 2, 1}, {1, 1, 1}, {2, 1, 0}}, []float64{5, 4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []float64{1, 2, 1} {
+		if math.Abs(x[i]-expected) > 1e-12 {
+			t.Errorf("Wrong solution %v", x)
+			break
+		}
+	}
+}
+
+// при 0,5 МПа и 228 К константы Вильсона дают однофазный газ, хотя точка лежит под кривой росы:
+// двухфазное состояние находится только анализом устойчивости
+func TestFlashStabilityAnalysis(t *testing.T) {
+	ctx := n1Context()
+	eos := newPengRobinson(defaultKij, rootGibbs)
+	ctx.p, ctx.t = 0.5, 228
+	z := getComposition(ctx)
+	k := make([]float64, len(z))
+	for i := range k {
+		k[i] = ctx.component(int32(i)).wilsonK(ctx.t, ctx.p)
+	}
+	if _, ok := rachfordRice(z, k); ok {
+		t.Fatal("Test point must be single phase by Wilson K")
+	}
+	env, err := eos.getPhaseEnvelope(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dew, err := eos.getDewPoint(ctx, env, ctx.p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dew <= ctx.t {
+		t.Fatalf("Test point must be below dew point %f", dew)
+	}
+	if _, unstable := eos.stability(ctx, z); !unstable {
+		t.Error("Expected unstable single phase below dew point")
+	}
+	fr, err := eos.flash(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fr.twoPhase() {
+		t.Errorf("Expected two phases below dew point %f; beta = %f", dew, fr.beta)
+	}
+	// выше точки росы состояние устойчиво
+	ctx.t = dew + 1
+	if _, unstable := eos.stability(ctx, z); unstable {
+		t.Errorf("Expected stable single phase above dew point %f", dew)
+	}
+}
+
+// прерванная диаграмма: крикондентерм и крикондебар берутся из построенных точек
+func TestPartialEnvelopeExtremes(t *testing.T) {
+	env := (&phaseEnvelope{points: []envelopePoint{
+		{t: 200, p: 0.1, dew: true},
+		{t: 250, p: 2, dew: true},
+		{t: 240, p: 4, dew: true},
+	}}).finish()
+	if env.cricondentherm.t < 250 || env.cricondenbar.p != 4 {
+		t.Errorf("Wrong extremes of partial envelope; cricondentherm t = %f, cricondenbar p = %f",
+			env.cricondentherm.t, env.cricondenbar.p)
+	}
+	var sb strings.Builder
+	if err := writeEnvelopeCSV(&sb, env, 0, 0, errors.New("tracing stopped")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sb.String(), "-273.15") {
+		t.Errorf("Partial envelope must not print zero extremes:\n%s", sb.String())
+	}
+	if !strings.HasPrefix(sb.String(), "# incomplete envelope: tracing stopped\n") {
+		t.Errorf("Partial envelope must start with incomplete comment:\n%s", sb.String())
+	}
+	sb.Reset()
+	if err := writeEnvelopeCSV(&sb, env, 0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sb.String(), "#") {
+		t.Errorf("Complete envelope must not contain comments:\n%s", sb.String())
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// EquationOfState - метод расчета свойств газа по составу, давлению и температуре из контекста.
+// Свойства, которые метод не рассчитывает, в результате равны нулю, см. hasExtendedProperties
+type EquationOfState interface {
+	// имя метода, под которым он выбирается флагом -model
+	Name() string
+	Properties(ctx *context) (*gasProperties, error)
+	// подготовка расчета для состава из контекста: величины, зависящие только от состава, вычисляются
+	// один раз. Результат рассчитывает свойства при давлении и температуре из переданного ему контекста,
+	// состав которого должен совпадать с составом при подготовке
+	Prepare(ctx *context) (preparedProperties, error)
+}
+
+// расчет свойств для подготовленного состава при давлении и температуре из контекста
+type preparedProperties func(ctx *context) (*gasProperties, error)
+
+// метод с подробным выводом промежуточных величин прямой задачи
+type detailedEquationOfState interface {
+	EquationOfState
+	calc(ctx *context, out *output) error
+}
+
+// настройки, общие для методов расчета
+type modelOptions struct {
+	// параметры бинарного взаимодействия и выбор корня кубических уравнений
+	kij  map[[2]*gasComponent]float64
+	root cubicRoot
+}
+
+// методы, рассчитывающие кроме z и плотности показатель адиабаты, скорость звука и вязкость.
+// Методы ГОСТ 30319.2 и AGA8 рассчитывают только z и плотность
+func hasExtendedProperties(model EquationOfState) bool {
+	switch model.(type) {
+	case gost3Model, *cubicEOS:
+		return true
+	}
+	return false
+}
+
+// реестр методов расчета по имени
+var models = map[string]func(opts *modelOptions) EquationOfState{
+	"gost3":  func(*modelOptions) EquationOfState { return gost3Model{} },
+	"aga8":   func(*modelOptions) EquationOfState { return aga8Model{} },
+	"nx19":   func(*modelOptions) EquationOfState { return nx19Model{} },
+	"gerg91": func(*modelOptions) EquationOfState { return gerg91Model{} },
+	"pr": func(opts *modelOptions) EquationOfState {
+		return newPengRobinson(opts.kij, opts.root)
+	},
+	"srk": func(opts *modelOptions) EquationOfState {
+		return newSoaveRedlichKwong(opts.kij, opts.root)
+	},
+}
+
+// порядок методов при сравнении, первый - базовый
+var modelOrder = []string{"gost3", "aga8", "nx19", "gerg91", "pr", "srk"}
+
+func newModel(name string, opts *modelOptions) (EquationOfState, error) {
+	newFunc, ok := models[name]
+	if !ok {
+		names := make([]string, 0, len(models))
+		for n := range models {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown model: %s, available: %s", name, strings.Join(names, ", "))
+	}
+	return newFunc(opts), nil
+}
+
+// ошибка в составе газа, в отличие от ошибок расчета зависит только от исходных данных
+type compositionError struct {
+	message string
+}
+
+func (e *compositionError) Error() string {
+	return e.message
+}
+
+// расчет в одной точке через подготовку состава
+func prepareAndCalc(model EquationOfState, ctx *context) (*gasProperties, error) {
+	prepared, err := model.Prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return prepared(ctx)
+}
+
+// проверка полноты состава для методов, которым нужен полный компонентный состав
+func checkFullComposition(ctx *context) error {
+	if total := sum(0, ctx.length(), ctx.fraction); math.Abs(total-1) > 0.01 {
+		return &compositionError{fmt.Sprintf("full composition required, sum of fractions is %f", total)}
+	}
+	return nil
+}
+
+// ГОСТ 30319.3
+type gost3Model struct{}
+
+func (gost3Model) Name() string { return "gost3" }
+
+func (model gost3Model) Properties(ctx *context) (*gasProperties, error) {
+	return prepareAndCalc(model, ctx)
+}
+
+func (gost3Model) Prepare(ctx *context) (preparedProperties, error) {
+	if err := checkFullComposition(ctx); err != nil {
+		return nil, err
+	}
+	m := newMixture(ctx)
+	return func(point *context) (*gasProperties, error) {
+		return getProperties(point, m)
+	}, nil
+}
+
+func (gost3Model) calc(ctx *context, out *output) error {
+	return calcPT(ctx, out)
+}
+
+// AGA8-92DC
+type aga8Model struct{}
+
+func (aga8Model) Name() string { return "aga8" }
+
+func (model aga8Model) Properties(ctx *context) (*gasProperties, error) {
+	return prepareAndCalc(model, ctx)
+}
+
+func (aga8Model) Prepare(ctx *context) (preparedProperties, error) {
+	if err := checkFullComposition(ctx); err != nil {
+		return nil, err
+	}
+	m := newAGA8Mixture(ctx)
+	return func(point *context) (*gasProperties, error) {
+		r, err := m.properties(point)
+		if err != nil {
+			return nil, err
+		}
+		return &gasProperties{z: r.z, density: r.density, iters: r.iters}, nil
+	}, nil
+}
+
+func (aga8Model) calc(ctx *context, out *output) error {
+	return calcAGA8(ctx, out)
+}
+
+// NX19 мод. по ГОСТ 30319.2
+type nx19Model struct{}
+
+func (nx19Model) Name() string { return "nx19" }
+
+func (model nx19Model) Properties(ctx *context) (*gasProperties, error) {
+	return prepareAndCalc(model, ctx)
+}
+
+func (nx19Model) Prepare(ctx *context) (preparedProperties, error) {
+	in, err := getGost303192Input(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(point *context) (*gasProperties, error) {
+		r, err := getNX19(in, point.p, point.t)
+		if err != nil {
+			return nil, err
+		}
+		return &gasProperties{z: r.z, density: r.density}, nil
+	}, nil
+}
+
+func (nx19Model) calc(ctx *context, out *output) error {
+	return calcNX19(ctx, out)
+}
+
+// GERG-91 мод. по ГОСТ 30319.2
+type gerg91Model struct{}
+
+func (gerg91Model) Name() string { return "gerg91" }
+
+func (model gerg91Model) Properties(ctx *context) (*gasProperties, error) {
+	return prepareAndCalc(model, ctx)
+}
+
+func (gerg91Model) Prepare(ctx *context) (preparedProperties, error) {
+	in, err := getGost303192Input(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(point *context) (*gasProperties, error) {
+		r, err := getGerg91(in, point.p, point.t)
+		if err != nil {
+			return nil, err
+		}
+		return &gasProperties{z: r.z, density: r.density}, nil
+	}, nil
+}
+
+func (gerg91Model) calc(ctx *context, out *output) error {
+	return calcGerg91(ctx, out)
+}
+
+// прямая задача: подробный вывод, если метод его поддерживает, иначе только плотность и коэффициент сжимаемости
+func calcModel(model EquationOfState) func(ctx *context, out *output) error {
+	if d, ok := model.(detailedEquationOfState); ok {
+		return d.calc
+	}
+	return func(ctx *context, out *output) error {
+		gp, err := model.Properties(ctx)
+		if err != nil {
+			return err
+		}
+		out.writeP(gp.density)
+		out.writeZ(gp.z)
+		return nil
+	}
+}
+
+// сравнение результатов всех методов расчета при одинаковых исходных данных
+func calcCompare(opts *modelOptions) func(ctx *context, out *output) error {
+	return func(ctx *context, out *output) error {
+		rows := make([]comparisonRow, 0, len(modelOrder))
+		for _, name := range modelOrder {
+			model, err := newModel(name, opts)
+			if err != nil {
+				return err
+			}
+			row := comparisonRow{model: name}
+			if gp, err := model.Properties(ctx); err != nil {
+				row.err = err
+			} else {
+				row.z, row.density = gp.z, gp.density
+			}
+			rows = append(rows, row)
+		}
+		out.writeComparison(rows)
+		return nil
+	}
+}
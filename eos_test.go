@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestModelRegistry(t *testing.T) {
+	opts := &modelOptions{kij: defaultKij, root: rootGibbs}
+	if len(modelOrder) != len(models) {
+		t.Errorf("Comparison order must list every model; order = %v", modelOrder)
+	}
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 300
+	for _, name := range modelOrder {
+		model, err := newModel(name, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if model.Name() != name {
+			t.Errorf("Model registered as %s has name %s", name, model.Name())
+		}
+		gp, err := model.Properties(ctx)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if gp.z <= 0 || gp.density <= 0 {
+			t.Errorf("%s: wrong properties z = %f, density = %f", name, gp.z, gp.density)
+		}
+	}
+	if _, err := newModel("gerg2008", opts); err == nil {
+		t.Error("Expected error for unknown model")
+	}
+}
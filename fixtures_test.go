@@ -0,0 +1,41 @@
+package main
+
+// составы газов N1 и N2 из ГОСТ 30319.3, общие для тестов
+var (
+	n1Fractions = []componentFraction{
+		{&methane, 0.965},
+		{&ethane, 0.018},
+		{&propane, 0.0045},
+		{&iButane, 0.001},
+		{&nButane, 0.001},
+		{&iPentane, 0.0005},
+		{&nPentane, 0.0003},
+		{&nHexane, 0.0007},
+		{&nitrogen, 0.003},
+		{&carbonDioxide, 0.006},
+	}
+	n2Fractions = []componentFraction{
+		{&methane, 0.812},
+		{&ethane, 0.043},
+		{&propane, 0.009},
+		{&iButane, 0.0015},
+		{&nButane, 0.0015},
+		{&nitrogen, 0.057},
+		{&carbonDioxide, 0.076},
+	}
+)
+
+// контекст с копией состава: тесты могут менять доли, не затрагивая общие составы
+func fractionsContext(fractions []componentFraction) *context {
+	ctx := &context{fractions: append([]componentFraction(nil), fractions...)}
+	ctx.initFractions()
+	return ctx
+}
+
+func n1Context() *context {
+	return fractionsContext(n1Fractions)
+}
+
+func n2Context() *context {
+	return fractionsContext(n2Fractions)
+}
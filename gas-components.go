@@ -1,25 +1,86 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 )
 
+// подкоманды, имя которых указывается первым аргументом командной строки
+var commands = map[string]func(args []string){
+	"table":       runTable,
+	"envelope":    runEnvelope,
+	"hydrate":     runHydrate,
+	"sensitivity": runSensitivity,
+	"serve":       runServe,
+	"modbus":      runModbus,
+	"stream":      runStream,
+	"repl":        runREPL,
+	"mix":         runMix,
+}
+
+// точка входа сборок без командной строки, например WebAssembly, задается при инициализации
+var platformMain func()
+
 func main() {
+	if platformMain != nil {
+		platformMain()
+		return
+	}
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
 	inputPath := flag.String("i", "", "путь к файлу с исходными данными")
 	outputPath := flag.String("o", "", "путь к файлу для вывода. Необязательно, по умолчанию используется стандартный поток вывода")
+	solveMode := flag.String("solve", "pt", "расчетная задача: pt - свойства по давлению и температуре, rhot - давление по плотности и температуре, prho - температура по давлению и плотности, ph - температура по давлению и энтальпии, ps - температура по давлению и энтропии")
+	modelName := flag.String("model", "gost3", "метод расчета: gost3 - ГОСТ 30319.3, nx19 - NX19 мод. по ГОСТ 30319.2, gerg91 - GERG-91 мод. по ГОСТ 30319.2, aga8 - AGA8-92DC, pr - Пенг-Робинсон, srk - Соаве-Редлих-Квонг")
+	kijPath := flag.String("kij", "", "путь к файлу с параметрами бинарного взаимодействия kij для моделей pr и srk. Необязательно, по умолчанию используются встроенные значения")
+	rootName := flag.String("root", "gibbs", "выбор корня кубического уравнения для моделей pr и srk: gibbs - с наименьшей энергией Гиббса, vapor - газовая фаза, liquid - жидкая фаза")
+	uncertaintyPath := flag.String("u", "", "путь к файлу со стандартными неопределенностями исходных данных для оценки неопределенности плотности и коэффициента сжимаемости методом Монте-Карло")
+	samples := flag.Int("n", 10000, "число испытаний Монте-Карло для оценки неопределенности")
+	seed := flag.Int64("seed", 1, "начальное значение генератора случайных чисел для оценки неопределенности")
+	strict := flag.Bool("strict", false, "отказываться от расчета, если исходные данные вне области применения ГОСТ 30319.3. По умолчанию выводится предупреждение")
+	compare := flag.Bool("compare", false, "сравнить результаты всех методов расчета при давлении и температуре из исходного файла")
+	stdTemperature := flag.Float64("std", 20, "температура стандартных условий в °С: 0, 15 или 20")
+	combustionTemperature := flag.Float64("comb", 25, "температура сгорания для расчета теплоты сгорания в °С: 0, 15, 20 или 25")
+	zcMethodName := flag.String("zc", "eos", "расчет коэффициента сжимаемости при стандартных условиях: eos - по уравнению состояния, sum - через коэффициенты суммирования компонентов")
+	watch := flag.Bool("watch", false, "наблюдать за исходным файлом и пересчитывать при каждом изменении. Результат записывается в файл вывода атомарно, при ошибке в исходных данных остается предыдущий")
+	pollInterval := flag.Duration("poll", 2*time.Second, "период опроса исходного файла в режиме -watch")
+	verbosityName := flag.String("v", "normal", "подробность вывода: quiet - только плотность и коэффициент сжимаемости, normal - с промежуточными величинами, trace - дополнительно невязки итераций, скорректированные доли и параметры бинарного взаимодействия")
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
 		flag.PrintDefaults()
 		var sb strings.Builder
+		sb.WriteString("\nПодкоманды:\n")
+		sb.WriteString("\ttable - таблицы свойств газа на сетке давлений и температур, подробнее: table -h\n")
+		sb.WriteString("\tenvelope - фазовая диаграмма, крикондентерм и точка росы по углеводородам, подробнее: envelope -h\n")
+		sb.WriteString("\thydrate - температура или давление образования гидратов, подробнее: hydrate -h\n")
+		sb.WriteString("\tmix - смешение потоков газа: состав смеси, вклад потоков в энергию и свойства смеси, подробнее: mix -h\n")
+		sb.WriteString("\tmodbus - Modbus TCP slave: исходные данные в holding-регистрах, свойства газа во input-регистрах, подробнее: modbus -h\n")
+		sb.WriteString("\trepl - интерактивный расчет: состав, давление и температура задаются командами, подробнее: repl -h\n")
+		sb.WriteString("\tstream - потоковый расчет: JSON-запрос в каждой строке ввода, ответ в строке вывода, подробнее: stream -h\n")
+		sb.WriteString("\tserve - HTTP JSON API для расчета свойств газа, подробнее: serve -h\n")
+		sb.WriteString("\tsensitivity - коэффициенты чувствительности z и плотности к составу, давлению и температуре, подробнее: sensitivity -h\n")
 		sb.WriteString("\nФормат исходного файла:\nКаждая строка состоит из имени компонента или параметра и его значения, разделенных пробелом.\nНапример: Метан 89,8211\n")
 		sb.WriteString("Названия параметров и компонентов:\n")
 		for _, c := range allComponents {
 			fmt.Fprintf(&sb, "\t%s\n", c.name)
 		}
-		sb.WriteString("\n\tt - температура в °С\n\tp - давление в МПа\n\n")
+		sb.WriteString("\n\tt - температура в °С\n\tp - давление в МПа\n\trho - плотность в кг/м^3, для обратных задач\n\th - удельная энтальпия в кДж/кг, для обратных задач\n\ts - удельная энтропия в кДж/(кг*К), для обратных задач\n")
+		sb.WriteString("\trhoc - плотность при стандартных условиях (20 °С) в кг/м^3, для методов ГОСТ 30319.2\n")
+		sb.WriteString("\tвлагосодержание - в мг/м^3 при стандартных условиях, для расчета точки росы по воде\n")
+		sb.WriteString("\tточка росы - температура точки росы по воде в °С, для расчета влагосодержания\n")
+		sb.WriteString("Файл неопределенностей (-u) имеет тот же формат: стандартные неопределенности долей компонентов в процентах, p в МПа и t в °С.\n")
+		sb.WriteString("Для методов ГОСТ 30319.2 достаточно указать rhoc, азот и диоксид углерода.\n")
+		sb.WriteString("Энтальпия и энтропия отсчитываются от идеального газа при 25 °С и 0,101325 МПа.\n\n")
 		sb.WriteString("Доли компонентов указываются в процентах.\nБольшие/маленькие буквы, точка или запятая в дробях - без разницы.\n\n")
 		sb.WriteString("Gas Components - made by Sleepy Plov with ♥\n")
 		fmt.Fprint(flag.CommandLine.Output(), sb.String())
@@ -30,32 +91,173 @@ func main() {
 		flag.Usage()
 		os.Exit(1)
 	}
+	level, err := parseVerbosity(*verbosityName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	std, err := newStandardConditions(*stdTemperature, *combustionTemperature, *zcMethodName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	root, ok := cubicRootByName[*rootName]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "unknown cubic root selection:", *rootName)
+		os.Exit(1)
+	}
+	kij, err := readKij(*kijPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	opts := &modelOptions{kij: kij, root: root}
+	model, err := newModel(*modelName, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var solve func(ctx *context, out *output) error
+	switch {
+	case *compare:
+		solve = calcCompare(opts)
+	case *uncertaintyPath != "":
+		u, err := readUncertainty(*uncertaintyPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		solve = calcUncertainty(model, u, *samples, *seed)
+	case *solveMode == "pt":
+		solve = calcModel(model)
+	case model.Name() != "gost3":
+		fmt.Fprintln(os.Stderr, "only pt solve mode is supported by model", model.Name())
+		os.Exit(1)
+	case *solveMode == "rhot":
+		solve = checkSolved(calcRhoT, *strict)
+	case *solveMode == "prho":
+		solve = checkSolved(calcPRho, *strict)
+	case *solveMode == "ph":
+		solve = checkSolved(calcPH, *strict)
+	case *solveMode == "ps":
+		solve = checkSolved(calcPS, *strict)
+	default:
+		fmt.Fprintln(os.Stderr, "unknown solve mode:", *solveMode)
+		os.Exit(1)
+	}
+	// проверка области применения, в строгом режиме - с ошибкой вне области
+	check := func(ctx *context) ([]rangeCheck, error) {
+		var checks []rangeCheck
+		if *compare || hasGost3Applicability(model) {
+			checks = checkApplicability(ctx)
+		}
+		if *strict {
+			if err := strictApplicability(checks); err != nil {
+				return nil, err
+			}
+		}
+		return checks, nil
+	}
+	if *watch {
+		if *pollInterval <= 0 {
+			fmt.Fprintln(os.Stderr, "poll interval must be positive")
+			os.Exit(1)
+		}
+		stop := make(chan struct{})
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			close(stop)
+		}()
+		watchInput(*inputPath, *outputPath, *pollInterval, level, func(ctx *context, out *output) error {
+			ctx.std = std
+			checks, err := check(ctx)
+			if err != nil {
+				return err
+			}
+			out.writeApplicability(checks)
+			return solve(ctx, out)
+		}, os.Stderr, stop)
+		return
+	}
 	ctx, err := readInput(*inputPath)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	out := newOutput(*outputPath)
-	defer out.close()
-	kx := getKx(ctx)
-	out.writeKx(kx)
-	p0m := getP0m(kx)
-	out.writeP0m(p0m)
-	mm := getMm(ctx)
-	out.writeMm(mm)
-	d, u := getDU(ctx, kx)
-	out.writeDU(d, u)
-	sigma := getInitialSigma(ctx, kx, d, u)
-	out.writeInitialSigma(sigma)
-	pi := getPi(ctx, p0m)
-	out.writePi(pi)
+	ctx.std = std
+	checks, err := check(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out, err := newOutput(*outputPath, level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out.writeApplicability(checks)
+	if err := solve(ctx, out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := out.close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// прямая задача: свойства газа по давлению и температуре
+func calcPT(ctx *context, out *output) error {
+	if ctx.p <= 0 || ctx.t <= 0 {
+		return errors.New("pressure and temperature must be set")
+	}
+	if err := checkFullComposition(ctx); err != nil {
+		return err
+	}
+	m := newMixture(ctx)
+	initialSigma := getInitialSigma(ctx, m.kx, m.d, m.u)
+	pi := getPi(ctx, m.p0m)
 	tau := getTau(ctx)
-	out.writeTau(tau)
-	iters := getSigma(ctx, kx, pi, tau, sigma, d, u)
-	out.writeSigmaIterations(iters)
-	sigma = iters[len(iters)-1].sigma
-	p := getP(ctx, kx, mm, sigma)
+	iters, err := getSigma(ctx, m.kx, pi, tau, initialSigma, m.d, m.u)
+	if err != nil {
+		return err
+	}
+	sigma := iters[len(iters)-1].sigma
+	p := getP(ctx, m.kx, m.mm, sigma)
+	z := getZ(ctx, sigma, tau, m.d, m.u)
+	if out.level >= trace {
+		out.writeFractions(ctx)
+		out.writeBinaryParams(ctx)
+	}
+	if out.level >= normal {
+		out.writeKx(m.kx)
+		out.writeP0m(m.p0m)
+		out.writeMm(m.mm)
+		out.writeDU(m.d, m.u)
+		out.writeInitialSigma(initialSigma)
+		out.writePi(pi)
+		out.writeTau(tau)
+		out.writeSigmaIterations(iters)
+	}
 	out.writeP(p)
-	z := getZ(ctx, sigma, tau, d, u)
 	out.writeZ(z)
+	if out.level >= normal && ctx.std != nil {
+		sp, err := getStandardProperties(ctx, m, ctx.std)
+		if err != nil {
+			return err
+		}
+		out.writeStandardProperties(ctx.std, sp, getVolumeCorrection(ctx, z, ctx.std, sp))
+		out.writeCalorificValues(ctx.std, getCalorificValues(ctx, m, ctx.std, sp))
+	}
+	if out.level >= normal && (ctx.waterContent > 0 || ctx.waterDewPoint > 0) {
+		wp, err := getWaterProperties(ctx)
+		if err != nil {
+			return err
+		}
+		out.writeWater(wp)
+	}
+	return nil
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"io"
 	"math"
 	"testing"
 )
@@ -242,18 +243,45 @@ var n3TestCases = []gasTestCase{
 }
 
 func getPZ(ctx *context) (p float64, z float64) {
-	kx := getKx(ctx)
-	p0m := getP0m(kx)
-	mm := getMm(ctx)
-	d, u := getDU(ctx, kx)
-	sigma := getInitialSigma(ctx, kx, d, u)
-	pi := getPi(ctx, p0m)
-	tau := getTau(ctx)
-	iters := getSigma(ctx, kx, pi, tau, sigma, d, u)
-	sigma = iters[len(iters)-1].sigma
-	p = getP(ctx, kx, mm, sigma)
-	z = getZ(ctx, sigma, tau, d, u)
-	return
+	gp, err := gost3Model{}.Properties(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return gp.density, gp.z
+}
+
+// расчеты ядра ГОСТ 30319.3 для тестов, где исходные данные заведомо в газовой области,
+// ошибка расчета - ошибка теста, как в getPZ
+func mustSigmaPT(ctx *context, m *mixture) float64 {
+	sigma, err := getSigmaPT(ctx, m)
+	if err != nil {
+		panic(err)
+	}
+	return sigma
+}
+
+func mustProperties(ctx *context, m *mixture) *gasProperties {
+	gp, err := getProperties(ctx, m)
+	if err != nil {
+		panic(err)
+	}
+	return gp
+}
+
+func mustAGA8(ctx *context) *aga8Result {
+	r, err := getAGA8(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func mustStandardProperties(ctx *context, m *mixture, sc *standardConditions) *standardProperties {
+	sp, err := getStandardProperties(ctx, m, sc)
+	if err != nil {
+		panic(err)
+	}
+	return sp
 }
 
 // returns the nearest number with the specified number of fraction digits
@@ -353,3 +381,120 @@ func TestGasN3(t *testing.T) {
 		}
 	}
 }
+
+func TestAllComponentsUnique(t *testing.T) {
+	seen := make(map[*gasComponent]bool)
+	for _, c := range allComponents {
+		if seen[c] {
+			t.Errorf("component %s is listed twice", c.name)
+		}
+		seen[c] = true
+	}
+	for name, c := range componentsByName {
+		if !seen[c] {
+			t.Errorf("component %s is missing from allComponents", name)
+		}
+	}
+}
+
+func TestCalcPTRejectsIncompleteInput(t *testing.T) {
+	ctx := n1Context()
+	if err := calcPT(ctx, newStreamOutput(io.Discard, normal)); err == nil {
+		t.Error("expected error without pressure and temperature")
+	}
+	ctx.p, ctx.t = 5, 300
+	ctx.fractions = ctx.fractions[:2]
+	if err := calcPT(ctx, newStreamOutput(io.Discard, normal)); err == nil {
+		t.Error("expected error for incomplete composition")
+	}
+	ctx = n1Context()
+	ctx.p, ctx.t = 5, 300
+	if err := calcPT(ctx, newStreamOutput(io.Discard, normal)); err != nil {
+		t.Error(err)
+	}
+}
+
+// показатель адиабаты численным дифференцированием по изоэнтропе: k = dln(p)/dln(rho) при s = const.
+// Энтропия считается через интегралы идеально-газовой теплоемкости и alphaR, независимо от A3 и cp0r
+func isentropicExponent(ctx *context, m *mixture) float64 {
+	sigma := mustSigmaPT(ctx, m)
+	s0 := getS(ctx, m.mm, sigma, getTau(ctx), m.d, m.u)
+	rho0 := getP(ctx, m.kx, m.mm, sigma)
+	// давление на изоэнтропе при плотности rho: температура подбирается методом секущих
+	pressure := func(rho float64) float64 {
+		point := *ctx
+		point.rho = rho
+		sigma := getSigmaFromDensity(&point, m.kx, m.mm)
+		s := func(t float64) float64 {
+			point.t = t
+			point.p = getPressureFromDensity(&point, m, sigma)
+			return getS(&point, m.mm, sigma, getTau(&point), m.d, m.u) - s0
+		}
+		t0, t1 := ctx.t, ctx.t*1.001
+		f0, f1 := s(t0), s(t1)
+		for i := 0; i < 50 && math.Abs(f1) > 1e-12; i++ {
+			t0, t1, f0 = t1, t1-f1*(t1-t0)/(f1-f0), f1
+			f1 = s(t1)
+		}
+		return point.p
+	}
+	const eps = 1e-4
+	return math.Log(pressure(rho0*(1+eps))/pressure(rho0*(1-eps))) / math.Log((1+eps)/(1-eps))
+}
+
+func TestIsentropicExponentConsistency(t *testing.T) {
+	ctx := n1Context()
+	m := newMixture(ctx)
+	for _, tc := range n1TestCases {
+		ctx.t, ctx.p = tc.t, tc.p
+		gp := mustProperties(ctx, m)
+		expected := isentropicExponent(ctx, m)
+		if !almostEqual(gp.k, expected, 1e-4*expected) {
+			t.Errorf("Wrong k for N1 at p = %g, t = %g; actual = %f, expected = %f", tc.p, tc.t, gp.k, expected)
+		}
+		// скорость звука связана с показателем адиабаты: w^2 = k*p/rho
+		w := math.Sqrt(expected * tc.p * 1e6 / gp.density)
+		if !almostEqual(gp.w, w, 1e-4*w) {
+			t.Errorf("Wrong w for N1 at p = %g, t = %g; actual = %f, expected = %f", tc.p, tc.t, gp.w, w)
+		}
+	}
+}
+
+// метан при 0,1 МПа: скорость звука и показатель адиабаты по справочным данным NIST для метана,
+// вязкость - по справочным данным для разреженного газа
+func TestMethaneCaloricProperties(t *testing.T) {
+	ctx := &context{}
+	ctx.fractions = []componentFraction{{&methane, 1}}
+	ctx.initFractions()
+	m := newMixture(ctx)
+	for _, tc := range []struct {
+		t, k, w, mu float64
+	}{
+		{250, 1.318, 413.1, 9.49},
+		{300, 1.304, 450.1, 11.18},
+	} {
+		ctx.t, ctx.p = tc.t, 0.1
+		gp := mustProperties(ctx, m)
+		if !almostEqual(gp.k, tc.k, 0.003*tc.k) {
+			t.Errorf("Wrong k for methane at t = %g; actual = %f, expected = %f", tc.t, gp.k, tc.k)
+		}
+		if !almostEqual(gp.w, tc.w, 0.002*tc.w) {
+			t.Errorf("Wrong w for methane at t = %g; actual = %f, expected = %f", tc.t, gp.w, tc.w)
+		}
+		if !almostEqual(gp.mu, tc.mu, 0.015*tc.mu) {
+			t.Errorf("Wrong mu for methane at t = %g; actual = %f, expected = %f", tc.t, gp.mu, tc.mu)
+		}
+	}
+}
+
+func TestSigmaIterationLimit(t *testing.T) {
+	// при 20 К метод Ньютона для газовой фазы не сходится, расчет должен завершиться ошибкой, а не зависнуть
+	ctx := &context{fractions: []componentFraction{{&methane, 1}}, p: 5, t: 20.15}
+	ctx.initFractions()
+	if _, err := getProperties(ctx, newMixture(ctx)); err == nil {
+		t.Error("Expected error for non-converging density iterations")
+	}
+	if _, err := getAGA8(ctx); err == nil {
+		t.Error("Expected AGA8 error for non-converging density iterations")
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Методы ГОСТ 30319.2 (NX19 мод. и GERG-91 мод.) для случая, когда полный состав газа неизвестен.
+// Исходные данные - плотность газа при стандартных условиях (20 °С, 0,101325 МПа)
+// и молярные доли азота и диоксида углерода.
+
+// универсальная газовая постоянная, принятая в ГОСТ 30319.2
+const gost303192R = 8.31451
+
+// молярный объем идеального газа при 20 °С и 0,101325 МПа, м^3/кмоль
+const gost303192Vm = 24.05525
+
+// исходные данные методов ГОСТ 30319.2
+type gost303192Input struct {
+	// плотность при стандартных условиях, кг/м^3
+	rhoc float64
+	// молярные доли азота и диоксида углерода
+	xa float64
+	xy float64
+}
+
+// результат расчета по методу ГОСТ 30319.2
+type gost303192Result struct {
+	// коэффициент сжимаемости при рабочих условиях
+	z float64
+	// коэффициент сжимаемости при стандартных условиях
+	zc float64
+	// плотность при рабочих условиях, кг/м^3
+	density float64
+}
+
+// исходные данные из контекста: плотность задается параметром rhoc или рассчитывается по полному составу
+func getGost303192Input(ctx *context) (*gost303192Input, error) {
+	in := &gost303192Input{rhoc: ctx.rhoc}
+	total := 0.0
+	for _, cf := range ctx.fractions {
+		total += cf.fraction
+		if cf.component == &nitrogen {
+			in.xa += cf.fraction
+		} else if cf.component == &carbonDioxide {
+			in.xy += cf.fraction
+		}
+	}
+	if in.rhoc == 0 {
+		if math.Abs(total-1) > 0.01 {
+			return nil, errors.New("rhoc must be set when full gas composition is unknown")
+		}
+		std := &standardConditions{t: 293.15, p: standardPressure, method: zcEOS}
+		sp, err := getStandardProperties(ctx, newMixture(ctx), std)
+		if err != nil {
+			return nil, err
+		}
+		in.rhoc = sp.density
+	}
+	if in.rhoc <= 0 {
+		return nil, errors.New("rhoc must be positive")
+	}
+	return in, nil
+}
+
+// коэффициент сжимаемости при стандартных условиях по плотности и содержанию азота и диоксида углерода
+func (in *gost303192Input) zc() float64 {
+	return 1 - math.Pow(0.0741*in.rhoc-0.006-0.063*in.xa-0.0575*in.xy, 2)
+}
+
+// плотность при рабочих условиях по плотности при стандартных условиях
+func (in *gost303192Input) density(p, t, z, zc float64) float64 {
+	return in.rhoc * p * 293.15 * zc / (standardPressure * t * z)
+}
+
+// функция E метода NX19 мод. для областей приведенных давления и температуры
+func nx19E(pa, dTau float64) float64 {
+	if dTau >= 0 {
+		return 1 - 0.00075*math.Pow(pa, 2.3)*math.Exp(-20*dTau) -
+			0.0011*math.Sqrt(dTau)*pa*pa*math.Pow(2.17-pa+1.4*math.Sqrt(dTau), 2)
+	}
+	if pa < 1.3 {
+		return 1 - 0.00075*math.Pow(pa, 2.3)*(2-math.Exp(20*dTau)) -
+			1.317*pa*(1.69-pa*pa)*math.Pow(dTau, 4)
+	}
+	return 1 - 0.00075*math.Pow(pa, 2.3)*(2-math.Exp(20*dTau)) +
+		0.455*(200*math.Pow(dTau, 6)-0.03249*dTau+2.0167*dTau*dTau-18.028*math.Pow(dTau, 3)+42.844*math.Pow(dTau, 4))*
+			(pa-1.3)*(1.69*math.Pow(2, 1.25)-pa*pa)
+}
+
+// область применения методов NX19 мод. и GERG-91 мод. по ГОСТ 30319.2, общая для обоих методов
+const (
+	gost303192MaxP    = 12
+	gost303192MinT    = 250
+	gost303192MaxT    = 340
+	gost303192MinRhoc = 0.668
+	gost303192MaxRhoc = 1.0
+	gost303192MaxXa   = 0.2
+	gost303192MaxXy   = 0.15
+)
+
+// проверка исходных данных по области применения метода ГОСТ 30319.2, method - имя метода для ошибки
+func checkGost303192Range(method string, in *gost303192Input, p, t float64) error {
+	switch {
+	case p <= 0 || p > gost303192MaxP:
+		return fmt.Errorf("%s: pressure %g MPa is outside 0..%g MPa", method, p, float64(gost303192MaxP))
+	case t < gost303192MinT || t > gost303192MaxT:
+		return fmt.Errorf("%s: temperature %g K is outside %g..%g K", method, t, float64(gost303192MinT), float64(gost303192MaxT))
+	case in.rhoc < gost303192MinRhoc || in.rhoc > gost303192MaxRhoc:
+		return fmt.Errorf("%s: rhoc %g kg/m^3 is outside %g..%g kg/m^3", method, in.rhoc, gost303192MinRhoc, gost303192MaxRhoc)
+	case in.xa > gost303192MaxXa:
+		return fmt.Errorf("%s: nitrogen fraction %g exceeds %g", method, in.xa, gost303192MaxXa)
+	case in.xy > gost303192MaxXy:
+		return fmt.Errorf("%s: carbon dioxide fraction %g exceeds %g", method, in.xy, gost303192MaxXy)
+	}
+	return nil
+}
+
+// проверка исходных данных и приведенных параметров по области применения NX19 мод.
+// Функция E определена для pa от 0 до 2 и dTau от -0,21 до 0,3, за ее пределами расчет дает
+// NaN или правдоподобные, но неверные значения
+func checkNX19Range(in *gost303192Input, p, t, pa, dTau float64) error {
+	if err := checkGost303192Range("NX19", in, p, t); err != nil {
+		return err
+	}
+	switch {
+	case pa < 0 || pa > 2:
+		return fmt.Errorf("NX19: reduced pressure pa = %g is outside 0..2", pa)
+	case dTau < -0.21 || dTau > 0.3:
+		return fmt.Errorf("NX19: reduced temperature difference dTau = %g is outside -0.21..0.3", dTau)
+	}
+	return nil
+}
+
+// расчет по методу NX19 мод., p - давление в МПа, t - температура в К
+func getNX19(in *gost303192Input, p, t float64) (*gost303192Result, error) {
+	// псевдокритические давление и температура
+	ppc := 2.9585 * (1.608 - 0.05994*in.rhoc + in.xy - 0.392*in.xa)
+	tpc := 88.25 * (0.9915 + 1.759*in.rhoc - in.xy - 1.681*in.xa)
+	pa := 0.6714*p/ppc + 0.0147
+	ta := 0.71892*t/tpc + 0.0007
+	dTau := ta - 1.09
+	if err := checkNX19Range(in, p, t, pa, dTau); err != nil {
+		return nil, err
+	}
+	m := 0.0330378*math.Pow(ta, -2) - 0.0221323*math.Pow(ta, -3) + 0.0161353*math.Pow(ta, -5)
+	n := (0.265827*math.Pow(ta, -2) + 0.0457697*math.Pow(ta, -4) - 0.133185/ta) / m
+	b := (3 - m*n*n) / (9 * m * pa * pa)
+	b0 := (9*n-2*m*n*n*n)/(54*m*pa*pa*pa) - nx19E(pa, dTau)/(2*m*pa*pa)
+	b1 := math.Cbrt(b0 + math.Sqrt(b0*b0+b*b*b))
+	// коэффициент сверхсжимаемости
+	fz := math.Sqrt(b/b1-b1+n/(3*pa)) / (1 + 0.00132/math.Pow(ta, 3.25))
+	r := &gost303192Result{z: 1 / (fz * fz), zc: in.zc()}
+	r.density = in.density(p, t, r.z, r.zc)
+	return r, nil
+}
+
+// квадратичная зависимость коэффициента уравнения GERG-91 от температуры
+func gerg91Poly(c [3]float64, t float64) float64 {
+	return c[0] + c[1]*t + c[2]*t*t
+}
+
+// коэффициенты уравнения GERG-91 мод.: эквивалентный углеводород, азот, диоксид углерода
+var (
+	gerg91B0   = [3]float64{-0.425468, 0.286500e-2, -0.462073e-5}
+	gerg91BH   = [3]float64{0.877118e-3, -0.556281e-5, 0.881510e-8}
+	gerg91BHH  = [3]float64{-0.824747e-6, 0.431436e-8, -0.608319e-11}
+	gerg91B22  = [3]float64{-0.144600, 0.740910e-3, -0.911950e-6}
+	gerg91B23  = [3]float64{-0.339693, 0.161176e-2, -0.204429e-5}
+	gerg91B33  = [3]float64{-0.868340, 0.403760e-2, -0.516570e-5}
+	gerg91C0   = [3]float64{-0.302488, 0.195861e-2, -0.316302e-5}
+	gerg91CH   = [3]float64{0.646422e-3, -0.422876e-5, 0.688157e-8}
+	gerg91CHH  = [3]float64{-0.332805e-6, 0.223160e-8, -0.367713e-11}
+	gerg91C222 = [3]float64{0.784980e-2, -0.398950e-4, 0.611870e-7}
+	gerg91C223 = [3]float64{0.552066e-2, -0.168609e-4, 0.157169e-7}
+	gerg91C233 = [3]float64{0.358783e-2, 0.806674e-5, -0.325798e-7}
+	gerg91C333 = [3]float64{0.205130e-2, 0.348880e-4, -0.837030e-7}
+)
+
+// второй (м^3/кмоль) и третий (м^6/кмоль^2) вириальные коэффициенты смеси,
+// h - теплота сгорания эквивалентного углеводорода, МДж/кмоль
+func getGerg91BC(in *gost303192Input, h, t float64) (b, c float64) {
+	x1, x2, x3 := 1-in.xa-in.xy, in.xa, in.xy
+	b11 := gerg91Poly(gerg91B0, t) + gerg91Poly(gerg91BH, t)*h + gerg91Poly(gerg91BHH, t)*h*h
+	b22 := gerg91Poly(gerg91B22, t)
+	b23 := gerg91Poly(gerg91B23, t)
+	b33 := gerg91Poly(gerg91B33, t)
+	b12 := (0.72 + 1.875e-5*math.Pow(320-t, 2)) * (b11 + b22) / 2
+	b13 := -0.865 * math.Sqrt(b11*b33)
+	b = x1*x1*b11 + 2*x1*x2*b12 + 2*x1*x3*b13 + x2*x2*b22 + 2*x2*x3*b23 + x3*x3*b33
+
+	c111 := gerg91Poly(gerg91C0, t) + gerg91Poly(gerg91CH, t)*h + gerg91Poly(gerg91CHH, t)*h*h
+	c222 := gerg91Poly(gerg91C222, t)
+	c223 := gerg91Poly(gerg91C223, t)
+	c233 := gerg91Poly(gerg91C233, t)
+	c333 := gerg91Poly(gerg91C333, t)
+	y := 0.92 + 0.0013*(t-270)
+	c112 := y * math.Cbrt(c111*c111*c222)
+	c113 := 0.92 * math.Cbrt(c111*c111*c333)
+	c122 := y * math.Cbrt(c111*c222*c222)
+	c123 := 1.1 * math.Cbrt(c111*c222*c333)
+	c133 := 1.2 * math.Cbrt(c111*c333*c333)
+	c = x1*x1*x1*c111 + 3*x1*x1*x2*c112 + 3*x1*x1*x3*c113 + 3*x1*x2*x2*c122 + 6*x1*x2*x3*c123 +
+		3*x1*x3*x3*c133 + x2*x2*x2*c222 + 3*x2*x2*x3*c223 + 3*x2*x3*x3*c233 + x3*x3*x3*c333
+	return
+}
+
+// решение вириального уравнения Z = 1 + B*rho + C*rho^2 относительно Z методом Ньютона
+func getGerg91Z(b, c, p, t float64) (float64, error) {
+	// приведенные вириальные коэффициенты
+	b0 := b * p * math.Pow10(3) / (gost303192R * t)
+	c0 := c * math.Pow(p*math.Pow10(3)/(gost303192R*t), 2)
+	z := 1 + b0
+	for i := 0; i < maxSolverIterations; i++ {
+		dz := (z*z*z - z*z - b0*z - c0) / (3*z*z - 2*z - b0)
+		z -= dz
+		if math.Abs(dz) < math.Pow10(-10) {
+			return z, nil
+		}
+	}
+	return z, fmt.Errorf("GERG-91 iterations did not converge in %d steps", maxSolverIterations)
+}
+
+// проверка исходных данных по области применения GERG-91 мод. Вириальное уравнение за ее пределами
+// сходится, но дает физически бессмысленные значения, поэтому расчет вне области не выполняется
+func checkGerg91Range(in *gost303192Input, p, t float64) error {
+	return checkGost303192Range("GERG-91", in, p, t)
+}
+
+// расчет по методу GERG-91 мод., p - давление в МПа, t - температура в К
+func getGerg91(in *gost303192Input, p, t float64) (*gost303192Result, error) {
+	if err := checkGerg91Range(in, p, t); err != nil {
+		return nil, err
+	}
+	x1 := 1 - in.xa - in.xy
+	zc := in.zc()
+	var h float64
+	// теплота сгорания эквивалентного углеводорода уточняется вместе с коэффициентом сжимаемости
+	// при стандартных условиях
+	for i := 0; ; i++ {
+		if i == maxSolverIterations {
+			return nil, fmt.Errorf("GERG-91 iterations did not converge in %d steps", maxSolverIterations)
+		}
+		mm := gost303192Vm * zc * in.rhoc
+		// молярная масса эквивалентного углеводорода
+		me := (mm - in.xa*nitrogen.m - in.xy*carbonDioxide.m) / x1
+		h = 128.64 + 47.479*me
+		b, c := getGerg91BC(in, h, 293.15)
+		zcNew, err := getGerg91Z(b, c, standardPressure, 293.15)
+		if err != nil {
+			return nil, err
+		}
+		if math.Abs(zcNew-zc) < math.Pow10(-10) {
+			zc = zcNew
+			break
+		}
+		zc = zcNew
+	}
+	b, c := getGerg91BC(in, h, t)
+	z, err := getGerg91Z(b, c, p, t)
+	if err != nil {
+		return nil, err
+	}
+	return &gost303192Result{z: z, zc: zc, density: in.density(p, t, z, zc)}, nil
+}
+
+// расчет свойств по методу NX19 мод.
+func calcNX19(ctx *context, out *output) error {
+	in, err := getGost303192Input(ctx)
+	if err != nil {
+		return err
+	}
+	r, err := getNX19(in, ctx.p, ctx.t)
+	if err != nil {
+		return err
+	}
+	return writeGost303192(ctx, out, in, r)
+}
+
+// расчет свойств по методу GERG-91 мод.
+func calcGerg91(ctx *context, out *output) error {
+	in, err := getGost303192Input(ctx)
+	if err != nil {
+		return err
+	}
+	r, err := getGerg91(in, ctx.p, ctx.t)
+	if err != nil {
+		return err
+	}
+	return writeGost303192(ctx, out, in, r)
+}
+
+func writeGost303192(ctx *context, out *output, in *gost303192Input, r *gost303192Result) error {
+	if out.level >= normal {
+		out.writeGost303192Input(in)
+		out.writeGost303192Zc(r.zc, r.z/r.zc)
+	}
+	out.writeP(r.density)
+	out.writeZ(r.z)
+	return nil
+}
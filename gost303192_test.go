@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// методы ГОСТ 30319.2 в области их применимости должны согласовываться с ГОСТ 30319.3
+func TestGost303192AgainstGost303193(t *testing.T) {
+	ctx := n1Context()
+	in, err := getGost303192Input(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(in.xa, 0.003, 1e-12) || !almostEqual(in.xy, 0.006, 1e-12) {
+		t.Errorf("Wrong xa or xy; xa = %f, xy = %f", in.xa, in.xy)
+	}
+	for _, tc := range n1TestCases {
+		nx19, err := getNX19(in, tc.p, tc.t)
+		if tc.p > gost303192MaxP || tc.t > gost303192MaxT {
+			if err == nil {
+				t.Errorf("NX19 must fail outside its range at p = %f, t = %f; z = %f", tc.p, tc.t, nx19.z)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx.p, ctx.t = tc.p, tc.t
+		_, z := getPZ(ctx)
+		// погрешность NX19 мод. вблизи нижней границы по температуре больше, чем у GERG-91 мод.
+		if math.Abs(nx19.z-z)/z > 0.01 {
+			t.Errorf("NX19 z deviates at p = %f, t = %f; nx19 = %f, gost3 = %f", tc.p, tc.t, nx19.z, z)
+		}
+		gerg91, err := getGerg91(in, tc.p, tc.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(gerg91.z-z)/z > 0.005 {
+			t.Errorf("GERG-91 z deviates at p = %f, t = %f; gerg91 = %f, gost3 = %f", tc.p, tc.t, gerg91.z, z)
+		}
+	}
+}
+
+func TestGost303192RequiresDensity(t *testing.T) {
+	ctx := &context{fractions: []componentFraction{{&nitrogen, 0.01}, {&carbonDioxide, 0.02}}}
+	if _, err := getGost303192Input(ctx); err == nil {
+		t.Error("Expected error when rhoc is missing and composition is incomplete")
+	}
+	ctx.rhoc = 0.7
+	in, err := getGost303192Input(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// при стандартных условиях коэффициент K = Z/Zc должен быть близок к единице
+	r, err := getNX19(in, standardPressure, 293.15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(r.z/r.zc, 1, 0.001) {
+		t.Errorf("NX19 K at standard conditions; actual = %f", r.z/r.zc)
+	}
+}
+
+func TestNX19Range(t *testing.T) {
+	in := &gost303192Input{rhoc: 0.7, xa: 0.01, xy: 0.02}
+	for _, tc := range []struct{ p, t float64 }{
+		{30, 250},
+		{40, 300},
+		{12.5, 300},
+		{5, 245},
+		{5, 345},
+	} {
+		if r, err := getNX19(in, tc.p, tc.t); err == nil {
+			t.Errorf("Expected range error at p = %g, t = %g; z = %f", tc.p, tc.t, r.z)
+		}
+	}
+	for _, bad := range []*gost303192Input{
+		{rhoc: 0.6, xa: 0.01, xy: 0.02},
+		{rhoc: 0.7, xa: 0.25, xy: 0.02},
+		{rhoc: 0.7, xa: 0.01, xy: 0.2},
+	} {
+		if _, err := getNX19(bad, 5, 300); err == nil {
+			t.Errorf("Expected range error for %+v", *bad)
+		}
+	}
+	if _, err := getNX19(in, 12, 250); err != nil {
+		t.Errorf("Boundary point must be accepted: %v", err)
+	}
+}
+
+func TestGerg91Range(t *testing.T) {
+	in := &gost303192Input{rhoc: 0.7, xa: 0.01, xy: 0.02}
+	for _, tc := range []struct{ p, t float64 }{
+		{60, 400},
+		{12.5, 300},
+		{5, 245},
+		{5, 345},
+		{0, 300},
+	} {
+		if r, err := getGerg91(in, tc.p, tc.t); err == nil {
+			t.Errorf("Expected range error at p = %g, t = %g; z = %f", tc.p, tc.t, r.z)
+		}
+	}
+	for _, bad := range []*gost303192Input{
+		{rhoc: 2.5, xa: 0.5, xy: 0.4},
+		{rhoc: 0.6, xa: 0.01, xy: 0.02},
+		{rhoc: 1.05, xa: 0.01, xy: 0.02},
+		{rhoc: 0.7, xa: 0.25, xy: 0.02},
+		{rhoc: 0.7, xa: 0.01, xy: 0.2},
+	} {
+		if r, err := getGerg91(bad, 5, 300); err == nil {
+			t.Errorf("Expected range error for %+v; z = %f", *bad, r.z)
+		}
+	}
+	for _, tc := range []struct{ p, t float64 }{{12, 250}, {12, 340}, {0.1, 340}} {
+		if _, err := getGerg91(in, tc.p, tc.t); err != nil {
+			t.Errorf("Boundary point p = %g, t = %g must be accepted: %v", tc.p, tc.t, err)
+		}
+	}
+	if _, err := getGerg91(&gost303192Input{rhoc: 1.0, xa: 0.2, xy: 0.15}, 5, 300); err != nil {
+		t.Errorf("Boundary composition must be accepted: %v", err)
+	}
+}
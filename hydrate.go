@@ -0,0 +1,311 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Температура и давление образования газовых гидратов по модели ван-дер-Ваальса-Платтеу.
+// Константы Ленгмюра - по Мунку и др. (1988), референтные свойства пустой решетки - по Холдеру.
+// Летучести компонентов газа рассчитываются по уравнению Пенга-Робинсона. Сероводород, который
+// также образует гидраты, в перечне компонентов отсутствует и не учитывается.
+// Ниже температуры плавления свободная вода - лед, и равновесие гидрата рассчитывается относительно льда.
+
+// константа Ленгмюра C = a/T * exp(b/T), a в К/атм, b в К
+type langmuirConstant struct {
+	a float64
+	b float64
+}
+
+func (c langmuirConstant) at(t float64) float64 {
+	if c.a == 0 {
+		return 0
+	}
+	return c.a / t * math.Exp(c.b/t)
+}
+
+// структура гидрата
+type hydrateStructure struct {
+	name string
+	// число малых и больших полостей на молекулу воды
+	nu [2]float64
+	// разность химических потенциалов воды в пустой решетке и жидкой воде при 273,15 К и нулевом давлении, Дж/моль
+	dMu0 float64
+	// разность энтальпий и объемов пустой решетки и жидкой воды при 273,15 К: Дж/моль, м^3/моль
+	dH0 float64
+	dV0 float64
+	// константы Ленгмюра для малых и больших полостей по компонентам
+	langmuir map[*gasComponent][2]langmuirConstant
+}
+
+// температура отсчета референтных свойств, К
+const hydrateT0 = 273.15
+
+// коэффициенты разности теплоемкостей пустой решетки и жидкой воды dCp = a + b*(T - T0), Дж/(моль*К)
+const (
+	hydrateCpA = -38.12
+	hydrateCpB = 0.141
+)
+
+// теплота плавления льда при 273,15 К, Дж/моль, и превышение молярного объема льда над объемом
+// жидкой воды, м^3/моль: разности свойств пустой решетки и льда по Холдеру получаются из разностей
+// относительно жидкой воды, разность теплоемкостей пустой решетки и льда принимается нулевой
+const (
+	iceFusionEnthalpy = 6009.5
+	iceExcessVolume   = 1.6e-6
+)
+
+// перевод МПа в атм для констант Ленгмюра
+const atmPerMPa = 9.86923
+
+var hydrateSI = &hydrateStructure{
+	name: "I",
+	nu:   [2]float64{1.0 / 23, 3.0 / 23},
+	dMu0: 1264,
+	dH0:  -4858,
+	dV0:  4.6e-6,
+	langmuir: map[*gasComponent][2]langmuirConstant{
+		&methane:       {{0.7228e-3, 3187}, {23.35e-3, 2653}},
+		&ethane:        {{0, 0}, {3.039e-3, 3861}},
+		&carbonDioxide: {{0.2474e-3, 3410}, {42.46e-3, 2813}},
+		&nitrogen:      {{1.617e-3, 2905}, {6.078e-3, 2431}},
+	},
+}
+
+var hydrateSII = &hydrateStructure{
+	name: "II",
+	nu:   [2]float64{2.0 / 17, 1.0 / 17},
+	dMu0: 883,
+	dH0:  -5201,
+	dV0:  5.0e-6,
+	langmuir: map[*gasComponent][2]langmuirConstant{
+		&methane:       {{0.2207e-3, 3453}, {100e-3, 1916}},
+		&ethane:        {{0, 0}, {240e-3, 2967}},
+		&propane:       {{0, 0}, {5.455e-3, 4638}},
+		&iButane:       {{0, 0}, {189.3e-3, 3800}},
+		&nButane:       {{0, 0}, {30.51e-3, 3699}},
+		&carbonDioxide: {{0.0845e-3, 3615}, {851e-3, 2025}},
+		&nitrogen:      {{0.1742e-3, 3082}, {18e-3, 1728}},
+	},
+}
+
+var hydrateStructures = []*hydrateStructure{hydrateSI, hydrateSII}
+
+// (μ пустой решетки - μ жидкой воды)/RT при температуре t, К, и давлении p, МПа
+func (hs *hydrateStructure) liquidPotential(t, p float64) float64 {
+	// dH(T) = c0 + c1*T + c2*T^2 после раскрытия интеграла теплоемкости
+	c0 := hs.dH0 - hydrateCpA*hydrateT0 + hydrateCpB*hydrateT0*hydrateT0/2
+	c1 := hydrateCpA - hydrateCpB*hydrateT0
+	c2 := hydrateCpB / 2
+	enthalpyIntegral := c0*(1/hydrateT0-1/t) + c1*math.Log(t/hydrateT0) + c2*(t-hydrateT0)
+	return hs.dMu0/(R*hydrateT0) - enthalpyIntegral/R + hs.dV0*p*math.Pow10(6)/(R*t)
+}
+
+// (μ пустой решетки - μ льда)/RT при температуре t, К, и давлении p, МПа
+func (hs *hydrateStructure) icePotential(t, p float64) float64 {
+	dH := hs.dH0 + iceFusionEnthalpy
+	dV := hs.dV0 - iceExcessVolume
+	return hs.dMu0/(R*hydrateT0) - dH*(1/hydrateT0-1/t)/R + dV*p*math.Pow10(6)/(R*t)
+}
+
+// (μ пустой решетки - μ воды в гидрате)/RT по степеням заполнения полостей, f - летучести компонентов в МПа
+func (hs *hydrateStructure) hydratePotential(ctx *context, f []float64, t float64) float64 {
+	result := 0.0
+	for m := 0; m < 2; m++ {
+		denom := 1.0
+		for i := int32(0); i < ctx.length(); i++ {
+			if c, ok := hs.langmuir[ctx.component(i)]; ok {
+				denom += c[m].at(t) * f[i] * atmPerMPa
+			}
+		}
+		// 1 - сумма степеней заполнения = 1/(1 + сумма C*f)
+		result += hs.nu[m] * math.Log(denom)
+	}
+	return result
+}
+
+// модель гидратообразования для состава газа
+type hydrateModel struct {
+	eos *cubicEOS
+	// мольная доля воды в газе, 0 - газ насыщен водой (есть свободная вода)
+	waterFraction float64
+}
+
+// движущая сила гидратообразования структуры hs: положительна, если гидрат устойчивее воды
+func (hm *hydrateModel) drivingForce(ctx *context, hs *hydrateStructure, t, p float64) float64 {
+	point := *ctx
+	point.t, point.p = t, p
+	m := hm.eos.newMixture(&point)
+	f := hm.eos.getFugacity(&point, m, hm.eos.solve(&point, m))
+	// конденсированная вода - жидкость или лед, устойчива фаза с меньшим химическим потенциалом воды,
+	// то есть с большей разностью потенциалов. Так учитывается и понижение температуры плавления льда
+	// с давлением: при давлениях гидратообразования лед плавится немного ниже 273,15 К
+	liquid := hs.liquidPotential(t, p)
+	reference := math.Max(liquid, hs.icePotential(t, p))
+	// активность воды относительно конденсированной воды, для насыщенного водой газа равна единице.
+	// Летучесть льда меньше летучести переохлажденной воды на разность их потенциалов
+	lnActivity := 0.0
+	if hm.waterFraction > 0 {
+		lnActivity = math.Log(hm.eos.gasWaterFugacity(ctx, hm.waterFraction, t, p)/
+			hm.eos.condensedWaterFugacity(t, p)) - (liquid - reference)
+		lnActivity = math.Min(0, lnActivity)
+	}
+	return hs.hydratePotential(&point, f, t) - (reference - lnActivity)
+}
+
+// диапазоны поиска температуры, К, и давления, МПа, образования гидратов
+const (
+	hydrateMinT = 200.0
+	hydrateMaxT = 320.0
+	hydrateMinP = 0.01
+	hydrateMaxP = 100.0
+)
+
+// результат расчета условий гидратообразования
+type hydrateResult struct {
+	// температура, К, и давление, МПа, равновесия
+	t float64
+	p float64
+	// структура образующегося гидрата
+	structure *hydrateStructure
+}
+
+func newHydrateModel(ctx *context) (*hydrateModel, error) {
+	formers := false
+	for i := int32(0); i < ctx.length(); i++ {
+		if _, ok := hydrateSII.langmuir[ctx.component(i)]; ok && ctx.fraction(i) > 0 {
+			formers = true
+		}
+	}
+	if !formers {
+		return nil, errors.New("gas contains no hydrate formers")
+	}
+	hm := &hydrateModel{eos: newPengRobinson(defaultKij, rootVapor)}
+	if ctx.waterContent > 0 || ctx.waterDewPoint > 0 {
+		wp, err := getWaterProperties(ctx)
+		if err != nil {
+			return nil, err
+		}
+		hm.waterFraction = wp.fraction
+	}
+	return hm, nil
+}
+
+// первый корень функции при движении от from к to: отрезок просматривается с постоянным шагом,
+// на первом отрезке со сменой знака корень уточняется делением пополам. Движущая сила
+// гидратообразования может быть немонотонной, например при конденсации пропана
+func scanRoot(from, to float64, f func(x float64) float64) (float64, bool) {
+	const steps = 60
+	h := (to - from) / steps
+	a, fa := from, f(from)
+	for k := 1; k <= steps; k++ {
+		b := from + float64(k)*h
+		fb := f(b)
+		if fa*fb <= 0 {
+			for i := 0; i < 100 && math.Abs(b-a) > 1e-9*math.Abs(b); i++ {
+				mid := (a + b) / 2
+				if fm := f(mid); fm*fa > 0 {
+					a, fa = mid, fm
+				} else {
+					b = mid
+				}
+			}
+			return (a + b) / 2, true
+		}
+		a, fa = b, fb
+	}
+	return 0, false
+}
+
+// температура образования гидратов при давлении p, МПа, - наибольшая по структурам
+func (hm *hydrateModel) getTemperature(ctx *context, p float64) (*hydrateResult, error) {
+	var best *hydrateResult
+	for _, hs := range hydrateStructures {
+		t, ok := scanRoot(hydrateMaxT, hydrateMinT, func(t float64) float64 {
+			return hm.drivingForce(ctx, hs, t, p)
+		})
+		if ok && (best == nil || t > best.t) {
+			best = &hydrateResult{t: t, p: p, structure: hs}
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no hydrate formation temperature in %g..%g °С at %g MPa",
+			hydrateMinT-273.15, hydrateMaxT-273.15, p)
+	}
+	return best, nil
+}
+
+// давление образования гидратов при температуре t, К, - наименьшее по структурам
+func (hm *hydrateModel) getPressure(ctx *context, t float64) (*hydrateResult, error) {
+	var best *hydrateResult
+	for _, hs := range hydrateStructures {
+		lnP, ok := scanRoot(math.Log(hydrateMinP), math.Log(hydrateMaxP), func(lnP float64) float64 {
+			return hm.drivingForce(ctx, hs, t, math.Exp(lnP))
+		})
+		if ok && (best == nil || math.Exp(lnP) < best.p) {
+			best = &hydrateResult{t: t, p: math.Exp(lnP), structure: hs}
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no hydrate formation pressure in %g..%g MPa at %g °С",
+			hydrateMinP, hydrateMaxP, t-273.15)
+	}
+	return best, nil
+}
+
+// подкоманда hydrate - температура образования гидратов при давлении или давление при температуре
+func runHydrate(args []string) {
+	fs := flag.NewFlagSet("hydrate", flag.ExitOnError)
+	inputPath := fs.String("i", "", "путь к файлу с составом газа, влагосодержанием или точкой росы по воде. Без них газ считается насыщенным водой")
+	outputPath := fs.String("o", "", "путь к файлу для вывода. Необязательно, по умолчанию используется стандартный поток вывода")
+	pressure := fs.Float64("p", 0, "давление в МПа, при котором рассчитывается температура образования гидратов. По умолчанию давление из исходного файла")
+	temperature := fs.Float64("t", 0, "температура в °С, при которой рассчитывается давление образования гидратов. Если задана, рассчитывается давление")
+	fs.Parse(args)
+	if *inputPath == "" {
+		fmt.Fprintln(os.Stderr, "missing input path")
+		fs.Usage()
+		os.Exit(1)
+	}
+	solvePressure := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "t" {
+			solvePressure = true
+		}
+	})
+	ctx, err := readInput(*inputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *pressure > 0 {
+		ctx.p = *pressure
+	}
+	hm, err := newHydrateModel(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var r *hydrateResult
+	if solvePressure {
+		r, err = hm.getPressure(ctx, *temperature+273.15)
+	} else {
+		r, err = hm.getTemperature(ctx, ctx.p)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out, err := newOutput(*outputPath, normal)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out.writeHydrate(r, solvePressure)
+	if err := out.close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import "testing"
+
+// экспериментальные давления образования гидратов чистых газов в присутствии воды
+func TestHydratePressurePureGases(t *testing.T) {
+	for _, tc := range []struct {
+		component *gasComponent
+		t, p      float64
+		structure *hydrateStructure
+	}{
+		{&methane, 273.15, 2.6, hydrateSI},
+		{&methane, 280.15, 5.3, hydrateSI},
+		{&ethane, 273.15, 0.53, hydrateSI},
+		{&carbonDioxide, 273.15, 1.22, hydrateSI},
+		{&propane, 273.15, 0.17, hydrateSII},
+	} {
+		ctx := &context{fractions: []componentFraction{{tc.component, 1}}}
+		hm, err := newHydrateModel(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := hm.getPressure(ctx, tc.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !almostEqual(r.p, tc.p, tc.p*0.1) || r.structure != tc.structure {
+			t.Errorf("Wrong hydrate pressure of %s at %f K; actual = %f (%s), expected = %f (%s)",
+				tc.component.name, tc.t, r.p, r.structure.name, tc.p, tc.structure.name)
+		}
+	}
+}
+
+func TestHydrateTemperatureRoundTrip(t *testing.T) {
+	ctx := n1Context()
+	hm, err := newHydrateModel(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := hm.getTemperature(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// пропан и бутаны стабилизируют структуру II
+	if r.structure != hydrateSII {
+		t.Errorf("Expected structure II for natural gas; actual = %s", r.structure.name)
+	}
+	back, err := hm.getPressure(ctx, r.t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(back.p, 5, 1e-4) {
+		t.Errorf("Wrong hydrate pressure at %f K; actual = %f, expected = 5", r.t, back.p)
+	}
+
+	// в газе, не насыщенном водой, гидраты образуются при более низкой температуре
+	ctx.p, ctx.waterContent = 5, 50
+	dry, err := newHydrateModel(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rd, err := dry.getTemperature(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rd.t >= r.t {
+		t.Errorf("Hydrate temperature of undersaturated gas %f must be below saturated %f", rd.t, r.t)
+	}
+}
+
+func TestHydrateRequiresFormers(t *testing.T) {
+	ctx := &context{fractions: []componentFraction{{&helium, 0.5}, {&hydrogen, 0.5}}}
+	if _, err := newHydrateModel(ctx); err == nil {
+		t.Error("Expected error for gas without hydrate formers")
+	}
+}
+
+// ниже температуры плавления равновесие рассчитывается относительно льда: давление непрерывно
+// при переходе, а его рост с температурой над льдом заметно медленнее, чем над жидкой водой
+func TestHydrateIceReference(t *testing.T) {
+	ctx := &context{fractions: []componentFraction{{&methane, 1}}}
+	hm, err := newHydrateModel(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pressure := func(temp float64) float64 {
+		r, err := hm.getPressure(ctx, temp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r.p
+	}
+	// экспериментальное давление равновесия метан - гидрат - лед около 1,9 МПа при -10 °С
+	if p := pressure(263.15); !almostEqual(p, 1.9, 1.9*0.1) {
+		t.Errorf("Wrong methane hydrate pressure over ice at 263.15 K; actual = %f, expected = 1.9", p)
+	}
+	prev := pressure(hydrateT0 - 1)
+	for temp := hydrateT0 - 0.95; temp < hydrateT0+0.5; temp += 0.05 {
+		p := pressure(temp)
+		if p < prev || p > prev*1.01 {
+			t.Errorf("Hydrate pressure must grow continuously near the ice point; %f at %f K after %f", p, temp, prev)
+		}
+		prev = p
+	}
+	iceSlope := (pressure(hydrateT0-1) - pressure(hydrateT0-3)) / 2
+	liquidSlope := (pressure(hydrateT0+3) - pressure(hydrateT0+1)) / 2
+	if iceSlope > liquidSlope/2 {
+		t.Errorf("Slope over ice %f must be well below slope over liquid water %f", iceSlope, liquidSlope)
+	}
+}
@@ -3,11 +3,36 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// разбор строки вида "имя значение", имя может состоять из нескольких слов.
+// Для пустой строки возвращается пустое имя
+func parseInputLine(line string) (string, float64, error) {
+	tokens := strings.Fields(line)
+	if len(tokens) == 0 {
+		return "", 0, nil
+	}
+	if len(tokens) < 2 {
+		return "", 0, fmt.Errorf("input line too short, missing name or value: %s", line)
+	}
+	iValue := -1
+	var value float64
+	for i, t := range tokens {
+		if v, err := strconv.ParseFloat(strings.ReplaceAll(t, ",", "."), 64); err == nil {
+			iValue, value = i, v
+		}
+	}
+	if iValue <= 0 {
+		return "", 0, fmt.Errorf("cannot parse value: %s", line)
+	}
+	return strings.ToLower(strings.Join(tokens[0:iValue], " ")), value, nil
+}
+
 func readInput(inputPath string) (*context, error) {
 	file, err := os.Open(inputPath)
 	if err != nil {
@@ -17,88 +42,155 @@ func readInput(inputPath string) (*context, error) {
 	sc := bufio.NewScanner(file)
 	ctx := context{}
 	for sc.Scan() {
-		line := sc.Text()
-		tokens := strings.Fields(line)
+		name, value, err := parseInputLine(sc.Text())
+		if err != nil {
+			return nil, err
+		}
 		// skip empty lines
-		if len(tokens) == 0 {
+		if name == "" {
 			continue
 		}
-		if len(tokens) < 2 {
-			return nil, fmt.Errorf("input line too short, missing name or value: %s", line)
-		}
-		iValue := -1
-		var (
-			value float64
-			err   error
-		)
-		for i, t := range tokens {
-			if value, err = strconv.ParseFloat(strings.ReplaceAll(t, ",", "."), 64); err == nil {
-				iValue = i
-			}
-		}
-		if iValue == -1 {
-			return nil, fmt.Errorf("cannot parse value: %s", line)
-		}
-		name := strings.ToLower(strings.Join(tokens[0:iValue], " "))
-		if name == "t" {
-			ctx.t = value + 273.15
-		} else if name == "p" {
-			ctx.p = value
-		} else if comp, ok := componentsByName[name]; ok {
-			// divide by 100 to convert percents into fraction
-			ctx.fractions = append(ctx.fractions, componentFraction{comp, value / 100})
-		} else {
-			return nil, fmt.Errorf("unknown component or parameter: %s", name)
+		if err := ctx.setInput(name, value); err != nil {
+			return nil, err
 		}
 	}
 	ctx.initFractions()
 	return &ctx, sc.Err()
 }
 
+// установка параметра или доли компонента (в %) из строки исходного файла
+func (c *context) setInput(name string, value float64) error {
+	if name == "t" {
+		c.t = value + 273.15
+	} else if name == "p" {
+		c.p = value
+	} else if name == "rhoc" {
+		c.rhoc = value
+	} else if name == "rho" {
+		c.rho = value
+	} else if name == "h" {
+		c.h, c.hSet = value, true
+	} else if name == "s" {
+		c.s, c.sSet = value, true
+	} else if name == "влагосодержание" {
+		c.waterContent = value
+	} else if name == "точка росы" {
+		c.waterDewPoint = value + 273.15
+	} else if comp, ok := componentsByName[name]; ok {
+		// divide by 100 to convert percents into fraction
+		c.fractions = append(c.fractions, componentFraction{comp, value / 100})
+	} else {
+		return fmt.Errorf("unknown component or parameter: %s", name)
+	}
+	return nil
+}
+
+// уровень подробности вывода
+type verbosity int
+
+const (
+	// только итоговые свойства газа
+	quiet verbosity = iota
+	// итоговые свойства и промежуточные величины расчета
+	normal
+	// дополнительно невязки итераций, скорректированные доли и параметры бинарного взаимодействия
+	trace
+)
+
+var verbosityByName = map[string]verbosity{
+	"quiet":  quiet,
+	"normal": normal,
+	"trace":  trace,
+}
+
+func parseVerbosity(name string) (verbosity, error) {
+	if v, ok := verbosityByName[strings.ToLower(name)]; ok {
+		return v, nil
+	}
+	return normal, fmt.Errorf("unknown verbosity level: %s", name)
+}
+
 type output struct {
+	w     io.Writer
+	level verbosity
+	// файл, открытый для вывода, закрывается в close. nil при выводе в поток
 	file *os.File
+	// первая ошибка записи: после нее вывод не выполняется, ошибка возвращается из close
+	err error
 }
 
-func newOutput(path string) *output {
-	var (
-		f   *os.File
-		err error
-	)
+func newOutput(path string, level verbosity) (*output, error) {
 	if path == "" {
-		f = os.Stdout
-	} else {
-		f, err = os.Create(path)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
+		return newStreamOutput(os.Stdout, level), nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &output{w: f, level: level, file: f}, nil
+}
+
+func newStreamOutput(w io.Writer, level verbosity) *output {
+	return &output{w: w, level: level}
+}
+
+func (o *output) print(a ...interface{}) {
+	if o.err == nil {
+		_, o.err = fmt.Fprint(o.w, a...)
+	}
+}
+
+func (o *output) printf(format string, a ...interface{}) {
+	if o.err == nil {
+		_, o.err = fmt.Fprintf(o.w, format, a...)
 	}
-	return &output{f}
 }
 
+// закрытие файла вывода, результат - первая ошибка записи или ошибка закрытия
 func (o *output) close() error {
-	return o.file.Close()
+	if o.file != nil {
+		if err := o.file.Close(); o.err == nil {
+			o.err = err
+		}
+		o.file = nil
+	}
+	return o.err
 }
 
-func (o *output) writeKx(kx float64) {
-	if _, err := fmt.Fprintf(o.file, "Смесевой параметр размера: Kx = %f м/кмоль^1/3\n", kx); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+func (o *output) writeFractions(ctx *context) {
+	var sb strings.Builder
+	sb.WriteString("Молярные доли компонентов после корректировки:\n")
+	for i := int32(0); i < ctx.length(); i++ {
+		fmt.Fprintf(&sb, "%2d  |  %s  |  %s\n", i+1, ctx.component(i).name, strconv.FormatFloat(ctx.fraction(i), 'f', -1, 64))
 	}
+	o.print(sb.String())
 }
 
-func (o *output) writeP0m(p0m float64) {
-	if _, err := fmt.Fprintf(o.file, "Давление нормировки: p0m = %f\n", p0m); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+func (o *output) writeBinaryParams(ctx *context) {
+	var sb strings.Builder
+	sb.WriteString("Параметры бинарного взаимодействия:\n")
+	sb.WriteString(" i  j  |  E         |  V         |  K         |  G         |  компоненты\n")
+	n := ctx.length()
+	for i := int32(0); i < n-1; i++ {
+		for j := i + 1; j < n; j++ {
+			fmt.Fprintf(&sb, "%2d %2d  |  %f  |  %f  |  %f  |  %f  |  %s - %s\n", i+1, j+1,
+				ctx.eBin(i, j), ctx.vBin(i, j), ctx.getKBin(i, j), ctx.gBin(i, j),
+				ctx.component(i).name, ctx.component(j).name)
+		}
 	}
+	o.print(sb.String())
+}
+
+func (o *output) writeKx(kx float64) {
+	o.printf("Смесевой параметр размера: Kx = %f м/кмоль^1/3\n", kx)
+}
+
+func (o *output) writeP0m(p0m float64) {
+	o.printf("Давление нормировки: p0m = %f\n", p0m)
 }
 
 func (o *output) writeMm(mm float64) {
-	if _, err := fmt.Fprintf(o.file, "Молярная масса газа: Mm = %f кг/кмоль\n", mm); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+	o.printf("Молярная масса газа: Mm = %f кг/кмоль\n", mm)
 }
 
 func (o *output) writeDU(d, u []float64) {
@@ -124,81 +216,307 @@ func (o *output) writeDU(d, u []float64) {
 		duStr[i] = fmt.Sprintf("%2d  |  %s  |  %s\n", i+1, dStr[i]+strings.Repeat(" ", maxDsLen-len(dStr[i])), uStr[i])
 	}
 	titleStr := fmt.Sprintf(" n  |  D%s  |  U\n", strings.Repeat(" ", maxDsLen-1))
-	if _, err := fmt.Fprint(o.file, "Функции молярных долей компонентов:\n", titleStr, strings.Join(duStr, "")); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+	o.print("Функции молярных долей компонентов:\n", titleStr, strings.Join(duStr, ""))
 }
 
 func (o *output) writePi(pi float64) {
-	if _, err := fmt.Fprintf(o.file, "Приведенное давление: %f\n", pi); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+	o.printf("Приведенное давление: %f\n", pi)
 }
 
 func (o *output) writeTau(tau float64) {
-	if _, err := fmt.Fprintf(o.file, "Приведенная температура: %f\n", tau); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+	o.printf("Приведенная температура: %f\n", tau)
 }
 
 func (o *output) writeInitialSigma(sigma float64) {
-	if _, err := fmt.Fprintf(o.file, "Начальное приближение приведенной плотности: %f\n", sigma); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
-	}
+	o.printf("Начальное приближение приведенной плотности: %f\n", sigma)
+}
+
+func (o *output) writeSigma(sigma float64) {
+	o.printf("Приведенная плотность: %f\n", sigma)
 }
 
 func (o *output) writeSigmaIterations(iters []sigmaIteration) {
 	n := len(iters)
-	dSigmaStr := make([]string, n)
-	sigmaStr := make([]string, n)
-	piCalcStr := make([]string, n)
+	steps := make([]float64, n)
+	values := make([]float64, n)
+	piCalc := make([]float64, n)
+	residuals := make([]float64, n)
+	for i, it := range iters {
+		steps[i], values[i], piCalc[i], residuals[i] = it.dSigma, it.sigma, it.piCalc, it.residual
+	}
+	o.writeIterations("Итерации расчета sigma:\n", "Δσ", "σ", "π расч.", steps, values, piCalc, residuals)
+}
+
+// вывод таблицы итераций: приращение, значение, расчетная контрольная величина и невязка
+func (o *output) writeIterations(title, stepName, valueName, calcName string, steps, values, calc, residuals []float64) {
+	n := len(steps)
+	stepStr := make([]string, n)
+	valueStr := make([]string, n)
+	calcStr := make([]string, n)
 	for i := 0; i < n; i++ {
-		dSigmaStr[i] = strconv.FormatFloat(iters[i].dSigma, 'f', -1, 64)
-		sigmaStr[i] = strconv.FormatFloat(iters[i].sigma, 'f', -1, 64)
-		piCalcStr[i] = strconv.FormatFloat(iters[i].piCalc, 'f', -1, 64)
+		stepStr[i] = strconv.FormatFloat(steps[i], 'f', -1, 64)
+		valueStr[i] = strconv.FormatFloat(values[i], 'f', -1, 64)
+		calcStr[i] = strconv.FormatFloat(calc[i], 'f', -1, 64)
 	}
-	maxLenDSigma := 0
-	maxLenSigma := 0
+	maxLenStep := utf8.RuneCountInString(stepName)
+	maxLenValue := utf8.RuneCountInString(valueName)
+	maxLenCalc := utf8.RuneCountInString(calcName)
 	for i := 0; i < n; i++ {
-		l := len(dSigmaStr[i])
-		if l > maxLenDSigma {
-			maxLenDSigma = l
+		if l := len(stepStr[i]); l > maxLenStep {
+			maxLenStep = l
+		}
+		if l := len(valueStr[i]); l > maxLenValue {
+			maxLenValue = l
 		}
-		l = len(sigmaStr[i])
-		if l > maxLenSigma {
-			maxLenSigma = l
+		if l := len(calcStr[i]); l > maxLenCalc {
+			maxLenCalc = l
 		}
 	}
 	var sb strings.Builder
-	sb.WriteString("Итерации расчета sigma:\n")
-	fmt.Fprintf(&sb, " k  |  Δσ%s  |  σ%s  |  π расч.\n",
-		strings.Repeat(" ", maxLenDSigma-2), strings.Repeat(" ", maxLenSigma-1))
+	sb.WriteString(title)
+	fmt.Fprintf(&sb, " k  |  %s%s  |  %s%s  |  %s",
+		stepName, strings.Repeat(" ", maxLenStep-utf8.RuneCountInString(stepName)),
+		valueName, strings.Repeat(" ", maxLenValue-utf8.RuneCountInString(valueName)), calcName)
+	if o.level >= trace {
+		fmt.Fprintf(&sb, "%s  |  невязка", strings.Repeat(" ", maxLenCalc-utf8.RuneCountInString(calcName)))
+	}
+	sb.WriteString("\n")
 	for i := 0; i < n; i++ {
 		fmt.Fprintf(&sb, "%2d  |", i+1)
-		fmt.Fprint(&sb, "  ", dSigmaStr[i], strings.Repeat(" ", maxLenDSigma-len(dSigmaStr[i])+2), "|")
-		fmt.Fprint(&sb, "  ", sigmaStr[i], strings.Repeat(" ", maxLenSigma-len(sigmaStr[i])+2), "|")
-		fmt.Fprint(&sb, "  ", piCalcStr[i], "\n")
-	}
-	if _, err := fmt.Fprint(o.file, sb.String()); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		fmt.Fprint(&sb, "  ", stepStr[i], strings.Repeat(" ", maxLenStep-len(stepStr[i])+2), "|")
+		fmt.Fprint(&sb, "  ", valueStr[i], strings.Repeat(" ", maxLenValue-len(valueStr[i])+2), "|")
+		fmt.Fprint(&sb, "  ", calcStr[i])
+		if o.level >= trace {
+			fmt.Fprint(&sb, strings.Repeat(" ", maxLenCalc-len(calcStr[i])+2), "|  ", strconv.FormatFloat(residuals[i], 'e', 3, 64))
+		}
+		sb.WriteString("\n")
 	}
+	o.print(sb.String())
 }
 
 func (o *output) writeP(p float64) {
-	if _, err := fmt.Fprintf(o.file, "Плотность газа p = %f кг/м^3\n", p); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	o.printf("Плотность газа p = %f кг/м^3\n", p)
+}
+
+func (o *output) writePressure(p float64) {
+	o.printf("Давление газа p = %f МПа\n", p)
+}
+
+func (o *output) writeTemperature(t float64) {
+	o.printf("Температура газа t = %f °С\n", t-273.15)
+}
+
+func (o *output) writeTauIterations(iters []tauIteration) {
+	n := len(iters)
+	steps := make([]float64, n)
+	values := make([]float64, n)
+	piCalc := make([]float64, n)
+	residuals := make([]float64, n)
+	for i, it := range iters {
+		steps[i], values[i], piCalc[i], residuals[i] = it.dTau, it.tau, it.piCalc, it.residual
 	}
+	o.writeIterations("Итерации расчета tau:\n", "Δτ", "τ", "π расч.", steps, values, piCalc, residuals)
+}
+
+func (o *output) writeTemperatureIterations(iters []temperatureIteration, calcName string) {
+	n := len(iters)
+	steps := make([]float64, n)
+	values := make([]float64, n)
+	calc := make([]float64, n)
+	residuals := make([]float64, n)
+	for i, it := range iters {
+		steps[i], values[i], calc[i], residuals[i] = it.dT, it.t, it.calc, it.residual
+	}
+	o.writeIterations("Итерации расчета температуры:\n", "ΔT", "T", calcName, steps, values, calc, residuals)
+}
+
+func (o *output) writeEnthalpy(h float64) {
+	o.printf("Удельная энтальпия h = %f кДж/кг\n", h)
+}
+
+func (o *output) writeEntropy(s float64) {
+	o.printf("Удельная энтропия s = %f кДж/(кг*К)\n", s)
+}
+
+func (o *output) writeStandardProperties(sc *standardConditions, sp *standardProperties, k float64) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Стандартные условия: t = %g °С, p = %g МПа\n", sc.t-273.15, sc.p)
+	fmt.Fprintf(&sb, "Коэффициент сжимаемости при стандартных условиях zc = %f\n", sp.z)
+	fmt.Fprintf(&sb, "Плотность газа при стандартных условиях pc = %f кг/м^3\n", sp.density)
+	fmt.Fprintf(&sb, "Молярный объем при стандартных условиях Vc = %f м^3/кмоль\n", sp.molarVolume)
+	fmt.Fprintf(&sb, "Коэффициент приведения к стандартным условиям K = %f\n", k)
+	o.print(sb.String())
+}
+
+func (o *output) writeCalorificValues(sc *standardConditions, cv *calorificValues) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Теплота сгорания при температуре сгорания %g °С и стандартных условиях %g °С:\n", sc.tComb, sc.t-273.15)
+	fmt.Fprintf(&sb, "\tмолярная высшая Hs = %f МДж/кмоль, низшая Hi = %f МДж/кмоль\n", cv.grossMolar, cv.netMolar)
+	fmt.Fprintf(&sb, "\tмассовая высшая Hs = %f МДж/кг, низшая Hi = %f МДж/кг\n", cv.grossMass, cv.netMass)
+	fmt.Fprintf(&sb, "\tобъемная высшая Hs = %f МДж/м^3, низшая Hi = %f МДж/м^3\n", cv.grossVolume, cv.netVolume)
+	fmt.Fprintf(&sb, "Число Воббе высшее Ws = %f МДж/м^3, низшее Wi = %f МДж/м^3\n", cv.grossWobbe, cv.netWobbe)
+	fmt.Fprintf(&sb, "Относительная плотность d = %f\n", cv.relativeDensity)
+	o.print(sb.String())
+}
+
+func (o *output) writeGost303192Input(in *gost303192Input) {
+	o.printf("Плотность газа при стандартных условиях pc = %f кг/м^3, доля азота xa = %f, доля диоксида углерода xy = %f\n",
+		in.rhoc, in.xa, in.xy)
+}
+
+func (o *output) writeGost303192Zc(zc, k float64) {
+	o.printf("Коэффициент сжимаемости при стандартных условиях zc = %f\nКоэффициент сжимаемости относительно стандартных условий K = %f\n", zc, k)
+}
+
+// результат расчета одним из методов для сравнения
+type comparisonRow struct {
+	model   string
+	z       float64
+	density float64
+	err     error
+}
+
+// вывод сравнения методов, отклонения считаются относительно первой строки
+func (o *output) writeComparison(rows []comparisonRow) {
+	var sb strings.Builder
+	sb.WriteString("Сравнение методов расчета:\n")
+	sb.WriteString(" метод     |  z         |  плотность, кг/м^3  |  δz, %      |  δρ, %\n")
+	base := rows[0]
+	for _, r := range rows {
+		if r.err != nil {
+			fmt.Fprintf(&sb, " %-8s  |  ошибка: %v\n", r.model, r.err)
+			continue
+		}
+		fmt.Fprintf(&sb, " %-8s  |  %f  |  %-17f  |", r.model, r.z, r.density)
+		if base.err == nil {
+			fmt.Fprintf(&sb, "  %+f  |  %+f", (r.z-base.z)/base.z*100, (r.density-base.density)/base.density*100)
+		}
+		sb.WriteString("\n")
+	}
+	o.print(sb.String())
+}
+
+func (o *output) writeCubicSolution(s *cubicSolution) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Приведенные параметры кубического уравнения A = %f, B = %f\n", s.aRed, s.bRed)
+	sb.WriteString("Корни уравнения относительно z:")
+	for _, z := range s.roots {
+		fmt.Fprintf(&sb, " %f", z)
+	}
+	sb.WriteString("\n")
+	o.print(sb.String())
+}
+
+func (o *output) writeFugacity(ctx *context, f []float64) {
+	var sb strings.Builder
+	sb.WriteString("Летучести компонентов, МПа:\n")
+	for i := int32(0); i < ctx.length(); i++ {
+		fmt.Fprintf(&sb, "%2d  |  %s  |  %f\n", i+1, ctx.component(i).name, f[i])
+	}
+	o.print(sb.String())
+}
+
+func (o *output) writeFlash(ctx *context, fr *flashResult) {
+	var sb strings.Builder
+	if !fr.twoPhase() {
+		sb.WriteString("Фазовое состояние: однофазное\n")
+	} else {
+		fmt.Fprintf(&sb, "Фазовое состояние: двухфазное, мольная доля газовой фазы %f\n", fr.beta)
+		sb.WriteString("Составы фаз, мольные доли:\n")
+		sb.WriteString(" №  |  компонент  |  жидкость  |  газ\n")
+		for i := int32(0); i < ctx.length(); i++ {
+			fmt.Fprintf(&sb, "%2d  |  %s  |  %f  |  %f\n", i+1, ctx.component(i).name, fr.x[i], fr.y[i])
+		}
+	}
+	o.print(sb.String())
+}
+
+func (o *output) writeWater(wp *waterProperties) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Влагосодержание W = %f мг/м^3 (при %g °С и %g МПа)\n", wp.content, wp.std.t-273.15, wp.std.p)
+	fmt.Fprintf(&sb, "Температура точки росы по воде при p = %g МПа: %f °С\n", wp.p, wp.dewPoint-273.15)
+	fmt.Fprintf(&sb, "Мольная доля воды в газе %g\n", wp.fraction)
+	sb.WriteString("Оценка с постоянными kij воды, температурные зависимости kij ISO 18453 не применяются\n")
+	o.print(sb.String())
+}
+
+func (o *output) writeHydrate(r *hydrateResult, pressure bool) {
+	if pressure {
+		o.printf("Давление образования гидратов при t = %g °С: %f МПа, структура %s\n", r.t-273.15, r.p, r.structure.name)
+	} else {
+		o.printf("Температура образования гидратов при p = %g МПа: %f °С, структура %s\n", r.p, r.t-273.15, r.structure.name)
+	}
+}
+
+func (o *output) writeUncertainty(model string, r *uncertaintyResult) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Неопределенность по методу Монте-Карло, метод расчета %s, испытаний %d", model, r.samples)
+	if r.failed > 0 {
+		fmt.Fprintf(&sb, ", из них с ошибкой расчета %d", r.failed)
+	}
+	sb.WriteString("\n")
+	fmt.Fprintf(&sb, " величина  |  среднее     |  u           |  u, %%      |  интервал охвата %g%%\n", coverageProbability*100)
+	for _, row := range []struct {
+		name string
+		s    uncertaintyStats
+	}{{"z", r.z}, {"ρ, кг/м^3", r.density}} {
+		fmt.Fprintf(&sb, " %-9s |  %-10f  |  %-10f  |  %-8f  |  [%f; %f]\n",
+			row.name, row.s.mean, row.s.u, row.s.u/row.s.mean*100, row.s.low, row.s.high)
+	}
+	o.print(sb.String())
 }
 
 func (o *output) writeZ(z float64) {
-	if _, err := fmt.Fprintf(o.file, "Коэффициент сжимаемости z = %f\n", z); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	o.printf("Коэффициент сжимаемости z = %f\n", z)
+}
+
+func (o *output) writeSensitivity(ctx *context, s *sensitivity) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Коэффициенты чувствительности при p = %g МПа, t = %g °С: z = %f, ρ = %f кг/м^3\n", ctx.p, ctx.t-273.15, s.z, s.density)
+	sb.WriteString("Производные по мольным долям компонентов (доли единицы):\n")
+	sb.WriteString(" №  |  компонент  |  ∂z/∂x        |  ∂ρ/∂x, кг/м^3\n")
+	for i := int32(0); i < ctx.length(); i++ {
+		fmt.Fprintf(&sb, "%2d  |  %s  |  %+e  |  %+e\n", i+1, ctx.component(i).name, s.dZdx[i], s.dRhodx[i])
+	}
+	fmt.Fprintf(&sb, "∂z/∂p = %+e 1/МПа, ∂ρ/∂p = %+e кг/(м^3*МПа)\n", s.dZdp, s.dRhodp)
+	fmt.Fprintf(&sb, "∂z/∂T = %+e 1/К, ∂ρ/∂T = %+e кг/(м^3*К)\n", s.dZdt, s.dRhodt)
+	o.print(sb.String())
+}
+
+// вывод смешения: расходы и вклад потоков в энергию, состав смеси - в формате исходного файла
+func (o *output) writeMixing(sc *standardConditions, r *mixingResult) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Смешение потоков, стандартные условия %g °С, температура сгорания %g °С:\n", sc.t-273.15, sc.tComb)
+	sb.WriteString(" №  |  кмоль/ч     |  кг/ч        |  м^3/ч       |  Hs, МДж/м^3  |  энергия, МДж/ч  |  доля энергии, %  |  поток\n")
+	for i, b := range r.streams {
+		// у негорючих газов энергия смеси нулевая, и доля энергии потока не определена
+		share := "-"
+		if r.energy > 0 {
+			share = fmt.Sprintf("%f", b.energy/r.energy*100)
+		}
+		fmt.Fprintf(&sb, "%2d  |  %-10f  |  %-10f  |  %-10f  |  %-11f  |  %-14f  |  %-15s  |  %s\n",
+			i+1, b.molar, b.mass, b.volume, b.grossVolume, b.energy, share, b.name)
+	}
+	fmt.Fprintf(&sb, "Смесь: %f кмоль/ч, %f кг/ч, энергия %f МДж/ч\n", r.molar, r.mass, r.energy)
+	sb.WriteString("Состав смеси, %:\n")
+	for _, cf := range sourceFractions(r.ctx) {
+		fmt.Fprintf(&sb, "%s %s\n", cf.component.name, formatInputValue(cf.fraction*100))
+	}
+	o.print(sb.String())
+}
+
+// результаты проверки области применения: при подробном выводе - все величины, иначе только предупреждения
+func (o *output) writeApplicability(checks []rangeCheck) {
+	var sb strings.Builder
+	if o.level >= trace && len(checks) > 0 {
+		sb.WriteString("Область применения ГОСТ 30319.3:\n")
+		for _, rc := range checks {
+			fmt.Fprintf(&sb, "  %s\n", rc)
+		}
+	} else {
+		for _, w := range applicabilityWarnings(checks) {
+			fmt.Fprintln(&sb, w)
+		}
 	}
+	o.print(sb.String())
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// писатель, который отказывает после первой записи
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes > 1 {
+		return 0, errors.New("disk full")
+	}
+	return len(p), nil
+}
+
+func TestOutputStickyError(t *testing.T) {
+	w := &failingWriter{}
+	out := newStreamOutput(w, normal)
+	out.writeZ(0.9)
+	out.writeP(40)
+	out.writeZ(0.9)
+	if w.writes != 2 {
+		t.Errorf("Output must stop after the first error; actual writes = %d", w.writes)
+	}
+	if err := out.close(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected first write error from close; actual = %v", err)
+	}
+}
+
+func calcPTOutput(t *testing.T, level verbosity) string {
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 300
+	var buf bytes.Buffer
+	out := newStreamOutput(&buf, level)
+	if err := calcPT(ctx, out); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestQuietVerbosity(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(calcPTOutput(t, quiet)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected density and z only, got:\n%s", strings.Join(lines, "\n"))
+	}
+	if !strings.HasPrefix(lines[0], "Плотность газа") {
+		t.Errorf("first line must be density: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "сжимаемости") {
+		t.Errorf("second line must be z: %s", lines[1])
+	}
+}
+
+func TestNormalVerbosity(t *testing.T) {
+	s := calcPTOutput(t, normal)
+	for _, want := range []string{"Итерации расчета sigma", "Функции молярных долей компонентов", "Плотность газа"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("normal output must contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"невязка", "после корректировки", "бинарного взаимодействия"} {
+		if strings.Contains(s, unwanted) {
+			t.Errorf("normal output must not contain %q", unwanted)
+		}
+	}
+}
+
+func TestTraceVerbosity(t *testing.T) {
+	s := calcPTOutput(t, trace)
+	for _, want := range []string{"невязка", "Молярные доли компонентов после корректировки", "Параметры бинарного взаимодействия"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("trace output must contain %q", want)
+		}
+	}
+	if !strings.Contains(s, "метан - этан") {
+		t.Error("trace output must list binary parameters by component pair")
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Смешение потоков газа в узле: состав смеси находится по молярным расходам потоков, расходы в массовых
+// и объемных единицах переводятся в молярные по молярной массе и молярному объему при стандартных условиях.
+// Вклад потока в энергию смеси - произведение молярного расхода на высшую молярную теплоту сгорания.
+
+// единица расхода потока
+type flowUnit int
+
+const (
+	// кмоль/ч
+	molarFlow flowUnit = iota
+	// кг/ч
+	massFlow
+	// м^3/ч при стандартных условиях
+	volumeFlow
+)
+
+var flowUnitByName = map[string]flowUnit{
+	"kmol": molarFlow,
+	"kg":   massFlow,
+	"m3":   volumeFlow,
+}
+
+// поток газа: состав из исходного файла и расход
+type gasStream struct {
+	name string
+	ctx  *context
+	flow float64
+	unit flowUnit
+}
+
+// разбор потока вида "путь:расход:единица". Путь может содержать двоеточие, например C:\gas.txt,
+// поэтому расход и единица берутся с конца
+func parseGasStream(s string) (*gasStream, error) {
+	iUnit := strings.LastIndex(s, ":")
+	if iUnit <= 0 {
+		return nil, fmt.Errorf("stream must be in form path:flow:unit: %s", s)
+	}
+	iFlow := strings.LastIndex(s[:iUnit], ":")
+	if iFlow <= 0 {
+		return nil, fmt.Errorf("stream must be in form path:flow:unit: %s", s)
+	}
+	unit, ok := flowUnitByName[strings.ToLower(s[iUnit+1:])]
+	if !ok {
+		return nil, fmt.Errorf("unknown flow unit, must be kmol, kg or m3: %s", s)
+	}
+	flow, err := strconv.ParseFloat(strings.ReplaceAll(s[iFlow+1:iUnit], ",", "."), 64)
+	if err != nil || flow < 0 {
+		return nil, fmt.Errorf("flow must be a non-negative number: %s", s)
+	}
+	return &gasStream{name: s[:iFlow], flow: flow, unit: unit}, nil
+}
+
+// поток после пересчета расходов
+type streamBalance struct {
+	name string
+	// молярный, кмоль/ч, массовый, кг/ч, и объемный при стандартных условиях, м^3/ч, расходы
+	molar  float64
+	mass   float64
+	volume float64
+	// высшая объемная теплота сгорания, МДж/м^3, и поток энергии, МДж/ч
+	grossVolume float64
+	energy      float64
+}
+
+// результат смешения
+type mixingResult struct {
+	streams []streamBalance
+	// смесь: состав в доли единицы и суммарные расходы
+	ctx    *context
+	molar  float64
+	mass   float64
+	energy float64
+}
+
+// состав потока в том виде, в котором задан, нормированный на единицу
+func normalizedFractions(ctx *context) []componentFraction {
+	fractions := sourceFractions(ctx)
+	total := sum(0, int32(len(fractions)), func(i int32) float64 { return fractions[i].fraction })
+	normalized := make([]componentFraction, len(fractions))
+	for i, cf := range fractions {
+		normalized[i] = componentFraction{cf.component, cf.fraction / total}
+	}
+	return normalized
+}
+
+// смешение потоков. Составы потоков должны быть полными, давление и температура потоков не используются
+func getMixing(streams []*gasStream, sc *standardConditions) (*mixingResult, error) {
+	if len(streams) == 0 {
+		return nil, errors.New("no streams to mix")
+	}
+	r := &mixingResult{streams: make([]streamBalance, len(streams))}
+	mixed := make(map[*gasComponent]float64)
+	for i, s := range streams {
+		if err := checkFullComposition(s.ctx); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.name, err)
+		}
+		m := newMixture(s.ctx)
+		sp, err := getStandardProperties(s.ctx, m, sc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.name, err)
+		}
+		cv := getCalorificValues(s.ctx, m, sc, sp)
+		b := streamBalance{name: s.name, grossVolume: cv.grossVolume}
+		switch s.unit {
+		case molarFlow:
+			b.molar = s.flow
+		case massFlow:
+			b.molar = s.flow / m.mm
+		case volumeFlow:
+			b.molar = s.flow / sp.molarVolume
+		}
+		b.mass = b.molar * m.mm
+		b.volume = b.molar * sp.molarVolume
+		b.energy = b.molar * cv.grossMolar
+		r.streams[i] = b
+		r.molar += b.molar
+		r.mass += b.mass
+		r.energy += b.energy
+		for _, cf := range normalizedFractions(s.ctx) {
+			mixed[cf.component] += b.molar * cf.fraction
+		}
+	}
+	if r.molar <= 0 {
+		return nil, errors.New("total flow must be positive")
+	}
+	r.ctx = &context{}
+	for _, comp := range allComponents {
+		if amount, ok := mixed[comp]; ok {
+			r.ctx.fractions = append(r.ctx.fractions, componentFraction{comp, amount / r.molar})
+		}
+	}
+	r.ctx.initFractions()
+	return r, nil
+}
+
+// подкоманда mix - смешение потоков газа и свойства смеси
+func runMix(args []string) {
+	fs := flag.NewFlagSet("mix", flag.ExitOnError)
+	outputPath := fs.String("o", "", "путь к файлу для вывода. Необязательно, по умолчанию используется стандартный поток вывода")
+	p := fs.Float64("p", 0, "давление смеси в МПа")
+	t := fs.Float64("t", 0, "температура смеси в °С")
+	modelName := fs.String("model", "gost3", "метод расчета свойств смеси, как у основной команды")
+	stdTemperature := fs.Float64("std", 20, "температура стандартных условий в °С: 0, 15 или 20")
+	combustionTemperature := fs.Float64("comb", 25, "температура сгорания для расчета теплоты сгорания в °С: 0, 15, 20 или 25")
+	strict := fs.Bool("strict", false, "отказываться от расчета, если смесь вне области применения ГОСТ 30319.3")
+	verbosityName := fs.String("v", "normal", "подробность вывода свойств смеси, как у основной команды: quiet, normal или trace")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: mix [флаги] путь:расход:единица ...")
+		fmt.Fprintln(fs.Output(), "Единица расхода: kmol - кмоль/ч, kg - кг/ч, m3 - м^3/ч при стандартных условиях.")
+		fmt.Fprintln(fs.Output(), "Исходный файл потока - в формате основной команды, давление и температура из него не используются.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "missing streams")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *p <= 0 || *t <= -273.15 {
+		fmt.Fprintln(os.Stderr, "pressure must be positive and temperature above absolute zero")
+		os.Exit(1)
+	}
+	level, err := parseVerbosity(*verbosityName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	std, err := newStandardConditions(*stdTemperature, *combustionTemperature, "eos")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	model, err := newModel(*modelName, &modelOptions{kij: defaultKij, root: rootGibbs})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	streams := make([]*gasStream, fs.NArg())
+	for i, arg := range fs.Args() {
+		s, err := parseGasStream(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if s.ctx, err = readInput(s.name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		streams[i] = s
+	}
+	r, err := getMixing(streams, std)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	ctx := r.ctx
+	ctx.p, ctx.t, ctx.std = *p, *t+273.15, std
+	var checks []rangeCheck
+	if hasGost3Applicability(model) {
+		checks = checkApplicability(ctx)
+	}
+	if *strict {
+		if err := strictApplicability(checks); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	out, err := newOutput(*outputPath, level)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out.writeMixing(std, r)
+	out.writeApplicability(checks)
+	if err := calcModel(model)(ctx, out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := out.close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseGasStream(t *testing.T) {
+	cases := []struct {
+		arg  string
+		name string
+		flow float64
+		unit flowUnit
+	}{
+		{"n1.txt:100:kmol", "n1.txt", 100, molarFlow},
+		{`C:\gas\n2.txt:1500,5:KG`, `C:\gas\n2.txt`, 1500.5, massFlow},
+		{"n3.txt:0:m3", "n3.txt", 0, volumeFlow},
+	}
+	for _, tc := range cases {
+		s, err := parseGasStream(tc.arg)
+		if err != nil {
+			t.Errorf("%s: %v", tc.arg, err)
+			continue
+		}
+		if s.name != tc.name || s.flow != tc.flow || s.unit != tc.unit {
+			t.Errorf("%s: unexpected stream %+v", tc.arg, s)
+		}
+	}
+	for _, arg := range []string{"n1.txt", "n1.txt:100", ":100:kmol", "n1.txt:-1:kmol", "n1.txt:x:kmol", "n1.txt:100:l"} {
+		if _, err := parseGasStream(arg); err == nil {
+			t.Errorf("%s: expected error", arg)
+		}
+	}
+}
+
+func TestGetMixing(t *testing.T) {
+	sc, err := newStandardConditions(20, 25, "eos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n1Ctx, n2Ctx := n1Context(), n2Context()
+	// расход второго потока задается в кг/ч так, чтобы молярный расход был равен 50 кмоль/ч
+	mm2 := getMm(n2Ctx)
+	r, err := getMixing([]*gasStream{
+		{name: "n1", ctx: n1Ctx, flow: 150, unit: molarFlow},
+		{name: "n2", ctx: n2Ctx, flow: 50 * mm2, unit: massFlow},
+	}, sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(r.streams[1].molar, 50, 1e-9) || !almostEqual(r.molar, 200, 1e-9) {
+		t.Errorf("Wrong molar flows: %+v, total %f", r.streams, r.molar)
+	}
+	expected := map[*gasComponent]float64{}
+	for _, cf := range n1Fractions {
+		expected[cf.component] += 0.75 * cf.fraction
+	}
+	for _, cf := range n2Fractions {
+		expected[cf.component] += 0.25 * cf.fraction
+	}
+	for _, cf := range r.ctx.fractions {
+		if !almostEqual(cf.fraction, expected[cf.component], 1e-12) {
+			t.Errorf("Wrong fraction of %s: expected %g, actual %g", cf.component.name, expected[cf.component], cf.fraction)
+		}
+	}
+	// энергия смеси равна сумме вкладов потоков
+	m := newMixture(r.ctx)
+	cv := getCalorificValues(r.ctx, m, sc, mustStandardProperties(r.ctx, m, sc))
+	if math.Abs(cv.grossMolar*r.molar-r.energy)/r.energy > 1e-9 {
+		t.Errorf("Energy balance mismatch: mixture %f, streams %f", cv.grossMolar*r.molar, r.energy)
+	}
+	if !almostEqual(r.mass, 150*getMm(n1Ctx)+50*mm2, 1e-6) {
+		t.Errorf("Wrong mass flow %f", r.mass)
+	}
+
+	// объемный расход переводится по молярному объему при стандартных условиях
+	r, err = getMixing([]*gasStream{{name: "n1", ctx: n1Ctx, flow: 1000, unit: volumeFlow}}, sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(r.streams[0].volume, 1000, 1e-9) || !almostEqual(r.streams[0].molar, 1000/24.04, 0.1) {
+		t.Errorf("Wrong volume flow conversion: %+v", r.streams[0])
+	}
+
+	if _, err := getMixing([]*gasStream{{name: "part", ctx: fractionsContext([]componentFraction{{&methane, 0.5}}), flow: 1}}, sc); err == nil {
+		t.Error("Expected error for incomplete composition")
+	}
+	if _, err := getMixing([]*gasStream{{name: "n1", ctx: n1Ctx, flow: 0}}, sc); err == nil {
+		t.Error("Expected error for zero total flow")
+	}
+}
+
+func TestWriteMixingWithoutEnergy(t *testing.T) {
+	sc, err := newStandardConditions(20, 25, "eos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := getMixing([]*gasStream{{name: "азот", ctx: fractionsContext([]componentFraction{{&nitrogen, 1}}), flow: 10}}, sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	newStreamOutput(&buf, normal).writeMixing(sc, r)
+	if strings.Contains(buf.String(), "NaN") {
+		t.Errorf("Energy share of non-combustible gas must not be NaN:\n%s", buf.String())
+	}
+}
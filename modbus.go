@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+)
+
+// Modbus TCP slave: давление, температура и доли компонентов записываются в holding-регистры,
+// после каждой записи свойства газа пересчитываются и публикуются во input-регистрах.
+// Все значения - числа IEEE-754 одинарной точности в двух регистрах, старшее слово первым.
+// Пересчет выполняется, когда запись завершает хотя бы одно значение, то есть затрагивает его младшее
+// слово: при записи функцией 6 по одному регистру половина нового числа со старшим словом не попадает
+// в расчет. Значения удобнее записывать функцией 16 целиком.
+
+// коды функций Modbus
+const (
+	modbusReadHolding   = 0x03
+	modbusReadInput     = 0x04
+	modbusWriteSingle   = 0x06
+	modbusWriteMultiple = 0x10
+)
+
+// коды исключений Modbus
+const (
+	modbusIllegalFunction = 0x01
+	modbusIllegalAddress  = 0x02
+	modbusIllegalValue    = 0x03
+	// признак исключения в коде функции ответа
+	modbusExceptionFlag = 0x80
+)
+
+const (
+	// ограничения числа регистров в одном запросе чтения и записи
+	modbusMaxReadRegisters  = 125
+	modbusMaxWriteRegisters = 123
+	// длина заголовка MBAP вместе с идентификатором устройства и максимальная длина кадра
+	modbusHeaderLength   = 7
+	modbusMaxFrameLength = 260
+	// число регистров адресного пространства и регистров на одно число с плавающей точкой
+	modbusRegisterSpaceSize = 1 << 16
+	modbusRegistersPerFloat = 2
+)
+
+// состояние расчета в регистре status
+const (
+	modbusStatusOK = iota
+	// расчет выполнен вне области применения ГОСТ 30319.3
+	modbusStatusWarning
+	// расчет не выполнен, значения свойств - NaN
+	modbusStatusError
+)
+
+// выходные величины и их значения в свойствах газа
+var modbusOutputs = []struct {
+	name  string
+	value func(gp *gasProperties) float64
+}{
+	{"z", func(gp *gasProperties) float64 { return gp.z }},
+	{"density", func(gp *gasProperties) float64 { return gp.density }},
+	{"k", func(gp *gasProperties) float64 { return gp.k }},
+	{"w", func(gp *gasProperties) float64 { return gp.w }},
+	{"mu", func(gp *gasProperties) float64 { return gp.mu }},
+}
+
+// карта регистров: адреса первых регистров величин
+type modbusRegisterMap struct {
+	// holding-регистры: p (МПа), t (°С) и доли компонентов (%) по названиям
+	holding map[string]uint16
+	// input-регистры: выходные величины и status - одиночный регистр с состоянием расчета
+	input map[string]uint16
+}
+
+// карта по умолчанию: p, t и компоненты в порядке allComponents подряд с нулевого holding-регистра,
+// выходные величины в порядке modbusOutputs и status с нулевого input-регистра
+func newDefaultModbusMap() *modbusRegisterMap {
+	rm := &modbusRegisterMap{holding: make(map[string]uint16), input: make(map[string]uint16)}
+	addr := uint16(0)
+	for _, name := range modbusInputNames() {
+		rm.holding[name] = addr
+		addr += modbusRegistersPerFloat
+	}
+	addr = 0
+	for _, out := range modbusOutputs {
+		rm.input[out.name] = addr
+		addr += modbusRegistersPerFloat
+	}
+	rm.input["status"] = addr
+	return rm
+}
+
+func modbusInputNames() []string {
+	names := []string{"p", "t"}
+	for _, c := range allComponents {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// чтение карты регистров в формате исходного файла: имя величины и адрес регистра.
+// Заданные в файле адреса заменяют адреса по умолчанию
+func readModbusMap(path string) (*modbusRegisterMap, error) {
+	rm := newDefaultModbusMap()
+	if path == "" {
+		return rm, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		name, value, err := parseInputLine(sc.Text())
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			continue
+		}
+		if value < 0 || value >= modbusRegisterSpaceSize-1 || value != math.Trunc(value) {
+			return nil, fmt.Errorf("invalid register address for %s: %g", name, value)
+		}
+		if _, ok := rm.holding[name]; ok {
+			rm.holding[name] = uint16(value)
+		} else if _, ok := rm.input[name]; ok {
+			rm.input[name] = uint16(value)
+		} else {
+			return nil, fmt.Errorf("unknown register name: %s", name)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return rm, rm.validate()
+}
+
+// проверка, что величины не перекрываются
+func (rm *modbusRegisterMap) validate() error {
+	// адреса сравниваются в int: у значения в конце адресного пространства сумма адреса и размера
+	// не помещается в uint16
+	check := func(kind string, regs map[string]uint16, size func(name string) int) error {
+		names := make([]string, 0, len(regs))
+		for name := range regs {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool { return regs[names[i]] < regs[names[j]] })
+		for i := 1; i < len(names); i++ {
+			if int(regs[names[i-1]])+size(names[i-1]) > int(regs[names[i]]) {
+				return fmt.Errorf("%s registers of %s and %s overlap", kind, names[i-1], names[i])
+			}
+		}
+		return nil
+	}
+	if err := check("holding", rm.holding, func(string) int { return modbusRegistersPerFloat }); err != nil {
+		return err
+	}
+	return check("input", rm.input, func(name string) int {
+		if name == "status" {
+			return 1
+		}
+		return modbusRegistersPerFloat
+	})
+}
+
+// запись регистров с адреса addr в количестве qty завершает значение в holding-регистрах
+func (rm *modbusRegisterMap) completesValue(addr, qty uint16) bool {
+	for _, first := range rm.holding {
+		last := int(first) + modbusRegistersPerFloat - 1
+		if last >= int(addr) && last < int(addr)+int(qty) {
+			return true
+		}
+	}
+	return false
+}
+
+// Modbus TCP slave с регистрами и методом расчета
+type modbusSlave struct {
+	mu      sync.Mutex
+	regs    *modbusRegisterMap
+	model   EquationOfState
+	holding []uint16
+	input   []uint16
+}
+
+func newModbusSlave(regs *modbusRegisterMap, model EquationOfState) *modbusSlave {
+	s := &modbusSlave{
+		regs:    regs,
+		model:   model,
+		holding: make([]uint16, modbusRegisterSpaceSize),
+		input:   make([]uint16, modbusRegisterSpaceSize),
+	}
+	s.recalculate()
+	return s
+}
+
+func getFloatRegister(regs []uint16, addr uint16) float64 {
+	return float64(math.Float32frombits(uint32(regs[addr])<<16 | uint32(regs[addr+1])))
+}
+
+func setFloatRegister(regs []uint16, addr uint16, v float64) {
+	bits := math.Float32bits(float32(v))
+	regs[addr] = uint16(bits >> 16)
+	regs[addr+1] = uint16(bits)
+}
+
+// запись исходных данных из контекста в holding-регистры
+func (s *modbusSlave) load(ctx *context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	setFloatRegister(s.holding, s.regs.holding["p"], ctx.p)
+	setFloatRegister(s.holding, s.regs.holding["t"], ctx.t-273.15)
+	for _, cf := range sourceFractions(ctx) {
+		setFloatRegister(s.holding, s.regs.holding[cf.component.name], cf.fraction*100)
+	}
+	s.recalculate()
+}
+
+// пересчет свойств по holding-регистрам, вызывается под блокировкой
+func (s *modbusSlave) recalculate() {
+	ctx := &context{
+		p: getFloatRegister(s.holding, s.regs.holding["p"]),
+		t: getFloatRegister(s.holding, s.regs.holding["t"]) + 273.15,
+	}
+	// в регистры можно записать NaN и бесконечность, на них итерации расчета не сходятся
+	valid := ctx.p > 0 && ctx.t > 0 && !math.IsInf(ctx.p, 0) && !math.IsInf(ctx.t, 0)
+	for _, c := range allComponents {
+		x := getFloatRegister(s.holding, s.regs.holding[c.name])
+		valid = valid && x >= 0 && !math.IsInf(x, 0)
+		if x != 0 {
+			ctx.fractions = append(ctx.fractions, componentFraction{c, x / 100})
+		}
+	}
+	ctx.initFractions()
+	status := modbusStatusOK
+	var gp *gasProperties
+	var err error
+	// расчет выполняется под блокировкой, поэтому за пределами расчета он не запускается,
+	// а итерации плотности ограничены: записанные мастером значения не могут остановить slave
+	if !valid {
+		err = errors.New("pressure, temperature and fractions must be set and finite")
+	} else if err = checkCalcLimits(s.model, ctx.p, ctx.t); err == nil {
+		gp, err = s.model.Properties(ctx)
+		if err == nil {
+			err = checkFiniteProperties(gp)
+		}
+	}
+	if err != nil {
+		status = modbusStatusError
+		gp = &gasProperties{z: math.NaN(), density: math.NaN(), k: math.NaN(), w: math.NaN(), mu: math.NaN()}
+	} else if hasGost3Applicability(s.model) && len(outOfRange(checkApplicability(ctx))) > 0 {
+		status = modbusStatusWarning
+	}
+	for _, out := range modbusOutputs {
+		setFloatRegister(s.input, s.regs.input[out.name], out.value(gp))
+	}
+	s.input[s.regs.input["status"]] = uint16(status)
+}
+
+func modbusException(fc, code byte) []byte {
+	return []byte{fc | modbusExceptionFlag, code}
+}
+
+// обработка PDU запроса, результат - PDU ответа
+func (s *modbusSlave) handle(pdu []byte) []byte {
+	if len(pdu) == 0 {
+		return modbusException(0, modbusIllegalFunction)
+	}
+	fc := pdu[0]
+	switch fc {
+	case modbusReadHolding, modbusReadInput:
+		if len(pdu) != 5 {
+			return modbusException(fc, modbusIllegalValue)
+		}
+		addr := binary.BigEndian.Uint16(pdu[1:])
+		qty := binary.BigEndian.Uint16(pdu[3:])
+		if qty == 0 || qty > modbusMaxReadRegisters {
+			return modbusException(fc, modbusIllegalValue)
+		}
+		if int(addr)+int(qty) > modbusRegisterSpaceSize {
+			return modbusException(fc, modbusIllegalAddress)
+		}
+		regs := s.holding
+		if fc == modbusReadInput {
+			regs = s.input
+		}
+		resp := []byte{fc, byte(2 * qty)}
+		s.mu.Lock()
+		for _, v := range regs[addr : addr+qty] {
+			resp = binary.BigEndian.AppendUint16(resp, v)
+		}
+		s.mu.Unlock()
+		return resp
+	case modbusWriteSingle:
+		if len(pdu) != 5 {
+			return modbusException(fc, modbusIllegalValue)
+		}
+		addr := binary.BigEndian.Uint16(pdu[1:])
+		s.mu.Lock()
+		s.holding[addr] = binary.BigEndian.Uint16(pdu[3:])
+		if s.regs.completesValue(addr, 1) {
+			s.recalculate()
+		}
+		s.mu.Unlock()
+		return append([]byte(nil), pdu...)
+	case modbusWriteMultiple:
+		if len(pdu) < 6 {
+			return modbusException(fc, modbusIllegalValue)
+		}
+		addr := binary.BigEndian.Uint16(pdu[1:])
+		qty := binary.BigEndian.Uint16(pdu[3:])
+		if qty == 0 || qty > modbusMaxWriteRegisters || int(pdu[5]) != 2*int(qty) || len(pdu) != 6+2*int(qty) {
+			return modbusException(fc, modbusIllegalValue)
+		}
+		if int(addr)+int(qty) > modbusRegisterSpaceSize {
+			return modbusException(fc, modbusIllegalAddress)
+		}
+		s.mu.Lock()
+		for i := uint16(0); i < qty; i++ {
+			s.holding[addr+i] = binary.BigEndian.Uint16(pdu[6+2*i:])
+		}
+		if s.regs.completesValue(addr, qty) {
+			s.recalculate()
+		}
+		s.mu.Unlock()
+		return append([]byte(nil), pdu[:5]...)
+	default:
+		return modbusException(fc, modbusIllegalFunction)
+	}
+}
+
+// обработка соединения: кадры MBAP с PDU, ответ с тем же идентификатором транзакции и устройства
+func (s *modbusSlave) serveConn(conn net.Conn) {
+	defer conn.Close()
+	header := make([]byte, modbusHeaderLength)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		length := binary.BigEndian.Uint16(header[4:])
+		// идентификатор протокола Modbus всегда 0
+		if binary.BigEndian.Uint16(header[2:]) != 0 || length < 2 ||
+			int(length)+modbusHeaderLength-1 > modbusMaxFrameLength {
+			return
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+		resp := s.handle(pdu)
+		frame := make([]byte, modbusHeaderLength, modbusHeaderLength+len(resp))
+		copy(frame, header[:4])
+		binary.BigEndian.PutUint16(frame[4:], uint16(len(resp)+1))
+		frame[6] = header[6]
+		if _, err := conn.Write(append(frame, resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// прием соединений до закрытия listener
+func (s *modbusSlave) serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// подкоманда modbus - Modbus TCP slave
+func runModbus(args []string) {
+	fs := flag.NewFlagSet("modbus", flag.ExitOnError)
+	addr := fs.String("addr", ":502", "адрес для входящих соединений")
+	mapPath := fs.String("map", "", "путь к файлу с картой регистров: имя величины и адрес первого регистра. Необязательно, по умолчанию карта из README")
+	inputPath := fs.String("i", "", "путь к файлу с начальными составом, давлением и температурой. Необязательно")
+	modelName := fs.String("model", "gost3", "метод расчета, как у основной команды")
+	fs.Parse(args)
+	regs, err := readModbusMap(*mapPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	model, err := newModel(*modelName, &modelOptions{kij: defaultKij, root: rootGibbs})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	s := newModbusSlave(regs, model)
+	if *inputPath != "" {
+		ctx, err := readInput(*inputPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		s.load(ctx)
+	}
+	l, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		l.Close()
+	}()
+	if err := s.serve(l); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
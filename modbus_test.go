@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// минимальный Modbus TCP клиент для проверки
+type modbusClient struct {
+	conn net.Conn
+	tid  uint16
+}
+
+func (c *modbusClient) request(t *testing.T, pdu []byte) []byte {
+	t.Helper()
+	c.tid++
+	frame := binary.BigEndian.AppendUint16(nil, c.tid)
+	frame = binary.BigEndian.AppendUint16(frame, 0)
+	frame = binary.BigEndian.AppendUint16(frame, uint16(len(pdu)+1))
+	frame = append(frame, 1)
+	if _, err := c.conn.Write(append(frame, pdu...)); err != nil {
+		t.Fatal(err)
+	}
+	header := make([]byte, modbusHeaderLength)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		t.Fatal(err)
+	}
+	if binary.BigEndian.Uint16(header) != c.tid || header[6] != 1 {
+		t.Fatalf("Wrong response header %v", header)
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(header[4:])-1)
+	if _, err := io.ReadFull(c.conn, resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func (c *modbusClient) writeFloats(t *testing.T, addr uint16, values ...float64) {
+	t.Helper()
+	regs := make([]uint16, 2*len(values))
+	for i, v := range values {
+		setFloatRegister(regs, uint16(2*i), v)
+	}
+	pdu := []byte{modbusWriteMultiple}
+	pdu = binary.BigEndian.AppendUint16(pdu, addr)
+	pdu = binary.BigEndian.AppendUint16(pdu, uint16(len(regs)))
+	pdu = append(pdu, byte(2*len(regs)))
+	for _, r := range regs {
+		pdu = binary.BigEndian.AppendUint16(pdu, r)
+	}
+	if resp := c.request(t, pdu); resp[0] != modbusWriteMultiple {
+		t.Fatalf("Write failed: %v", resp)
+	}
+}
+
+func (c *modbusClient) readInput(t *testing.T, addr, qty uint16) []uint16 {
+	t.Helper()
+	pdu := []byte{modbusReadInput}
+	pdu = binary.BigEndian.AppendUint16(pdu, addr)
+	pdu = binary.BigEndian.AppendUint16(pdu, qty)
+	resp := c.request(t, pdu)
+	if resp[0] != modbusReadInput || int(resp[1]) != 2*int(qty) {
+		t.Fatalf("Read failed: %v", resp)
+	}
+	regs := make([]uint16, qty)
+	for i := range regs {
+		regs[i] = binary.BigEndian.Uint16(resp[2+2*i:])
+	}
+	return regs
+}
+
+func startModbus(t *testing.T, regs *modbusRegisterMap) *modbusClient {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newModbusSlave(regs, gost3Model{})
+	go s.serve(l)
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		l.Close()
+	})
+	return &modbusClient{conn: conn}
+}
+
+func TestModbusCalculation(t *testing.T) {
+	regs := newDefaultModbusMap()
+	c := startModbus(t, regs)
+	if status := c.readInput(t, regs.input["status"], 1)[0]; status != modbusStatusError {
+		t.Errorf("Expected error status before inputs are written; actual = %d", status)
+	}
+	// состав N1 по регистрам компонентов, затем p и t
+	for _, cf := range n1Fractions {
+		c.writeFloats(t, regs.holding[cf.component.name], cf.fraction*100)
+	}
+	c.writeFloats(t, regs.holding["p"], 5, 10)
+	out := c.readInput(t, 0, 11)
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 283.15
+	expected, err := gost3Model{}.Properties(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out[10] != modbusStatusOK {
+		t.Errorf("Expected OK status; actual = %d", out[10])
+	}
+	for i, v := range []float64{expected.z, expected.density, expected.k, expected.w, expected.mu} {
+		if actual := getFloatRegister(out, uint16(2*i)); math.Abs(actual-v) > 1e-6*math.Abs(v) {
+			t.Errorf("Wrong %s; expected = %f, actual = %f", modbusOutputs[i].name, v, actual)
+		}
+	}
+	// запись давления одиночными регистрами, 35 МПа - вне области применения
+	var p35 [2]uint16
+	setFloatRegister(p35[:], 0, 35)
+	for i, r := range p35 {
+		pdu := []byte{modbusWriteSingle}
+		pdu = binary.BigEndian.AppendUint16(pdu, regs.holding["p"]+uint16(i))
+		pdu = binary.BigEndian.AppendUint16(pdu, r)
+		if resp := c.request(t, pdu); string(resp) != string(pdu) {
+			t.Fatalf("Write single register must echo request; actual = %v", resp)
+		}
+		// после старшего слова число еще не записано, расчет не обновляется
+		if status := c.readInput(t, regs.input["status"], 1)[0]; i == 0 && status != modbusStatusOK {
+			t.Errorf("Half-written pressure must not be calculated; status = %d", status)
+		}
+	}
+	if status := c.readInput(t, regs.input["status"], 1)[0]; status != modbusStatusWarning {
+		t.Errorf("Expected warning status out of range; actual = %d", status)
+	}
+}
+
+func TestModbusExceptions(t *testing.T) {
+	c := startModbus(t, newDefaultModbusMap())
+	for _, test := range []struct {
+		pdu  []byte
+		code byte
+	}{
+		{[]byte{0x2b, 0x0e, 0x01, 0x00}, modbusIllegalFunction},
+		{[]byte{modbusReadHolding, 0x00, 0x00, 0x00, 0x00}, modbusIllegalValue},
+		{[]byte{modbusReadInput, 0xff, 0xff, 0x00, 0x02}, modbusIllegalAddress},
+		{[]byte{modbusWriteMultiple, 0x00, 0x00, 0x00, 0x02, 0x02, 0x00, 0x00}, modbusIllegalValue},
+	} {
+		resp := c.request(t, test.pdu)
+		if len(resp) != 2 || resp[0] != test.pdu[0]|modbusExceptionFlag || resp[1] != test.code {
+			t.Errorf("Expected exception %d for %v; actual = %v", test.code, test.pdu, resp)
+		}
+	}
+}
+
+func TestModbusMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "map.txt")
+	if err := os.WriteFile(path, []byte("p 100\nz 200\nstatus 300\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	regs, err := readModbusMap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regs.holding["p"] != 100 || regs.input["z"] != 200 || regs.input["status"] != 300 || regs.holding["t"] != 2 {
+		t.Errorf("Wrong register map %+v", regs)
+	}
+	if err := os.WriteFile(path, []byte("p 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readModbusMap(path); err == nil {
+		t.Error("Expected overlap error for p and t")
+	}
+	// у значений в конце адресного пространства сумма адреса и размера выходит за uint16
+	if err := os.WriteFile(path, []byte("p 65534\nt 65534\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readModbusMap(path); err == nil {
+		t.Error("Expected overlap error for p and t at the end of address space")
+	}
+}
+
+func TestModbusCalculationLimits(t *testing.T) {
+	regs := newDefaultModbusMap()
+	c := startModbus(t, regs)
+	for _, cf := range n1Fractions {
+		c.writeFloats(t, regs.holding[cf.component.name], cf.fraction*100)
+	}
+	// при -253 °С итерации плотности не сходятся: slave выставляет ошибку и продолжает отвечать
+	c.writeFloats(t, regs.holding["p"], 5, -253)
+	if status := c.readInput(t, regs.input["status"], 1)[0]; status != modbusStatusError {
+		t.Errorf("Expected error status at -253 °C; actual = %d", status)
+	}
+	c.writeFloats(t, regs.holding["t"], 10)
+	if status := c.readInput(t, regs.input["status"], 1)[0]; status != modbusStatusOK {
+		t.Errorf("Expected OK status after valid temperature; actual = %d", status)
+	}
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Интерактивный режим: состав, давление и температура задаются командами, свойства газа
+// пересчитываются и выводятся после каждого изменения. Строки исходного файла задаются командой set.
+
+const replHelp = `Команды:
+  set <строка исходного файла>  - задать долю компонента в %% или параметр, например: set метан 90, set p 5
+  unset <компонент>             - удалить компонент из состава
+  model <метод>                 - метод расчета: %s
+  sweep p|t <начало> <конец> <шаг> - свойства при изменении давления в МПа или температуры в °С
+  show [input|du]               - свойства газа, исходные данные или функции Dn и Un по ГОСТ 30319.3
+  load <файл>                   - загрузить исходный файл
+  save <файл>                   - сохранить исходные данные в формате исходного файла
+  help                          - список команд
+  quit                          - выход
+`
+
+type repl struct {
+	// исходные данные, доли компонентов - в том виде, в котором заданы, без корректировки initFractions
+	input *context
+	model EquationOfState
+	opts  *modelOptions
+	out   *output
+}
+
+func newREPL(input *context, model EquationOfState, opts *modelOptions, w io.Writer) *repl {
+	return &repl{input: input, model: model, opts: opts, out: newStreamOutput(w, normal)}
+}
+
+// контекст расчета из текущих исходных данных
+func (r *repl) point() *context {
+	ctx := *r.input
+	ctx.fractions = append([]componentFraction(nil), r.input.fractions...)
+	ctx.initFractions()
+	return &ctx
+}
+
+// сумма долей компонентов, %
+func (r *repl) total() float64 {
+	return sum(0, int32(len(r.input.fractions)), func(i int32) float64 { return r.input.fractions[i].fraction * 100 })
+}
+
+func (r *repl) removeComponent(comp *gasComponent) bool {
+	for i, cf := range r.input.fractions {
+		if cf.component == comp {
+			r.input.fractions = append(r.input.fractions[:i], r.input.fractions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (r *repl) set(line string) error {
+	name, value, err := parseInputLine(line)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errors.New("missing name and value")
+	}
+	// повторное задание компонента заменяет прежнюю долю, не меняя порядок состава
+	if comp, ok := componentsByName[name]; ok {
+		for i := range r.input.fractions {
+			if r.input.fractions[i].component == comp {
+				r.input.fractions[i].fraction = value / 100
+				return nil
+			}
+		}
+	}
+	return r.input.setInput(name, value)
+}
+
+func (r *repl) unset(name string) error {
+	comp, ok := componentsByName[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown component: %s", name)
+	}
+	if !r.removeComponent(comp) {
+		return fmt.Errorf("component is not set: %s", name)
+	}
+	return nil
+}
+
+// свойства газа при текущих исходных данных. Пока не заданы состав, давление и температура,
+// выводится только то, чего не хватает
+func (r *repl) showProperties() error {
+	var missing []string
+	if len(r.input.fractions) == 0 {
+		missing = append(missing, "состав")
+	}
+	if r.input.p <= 0 {
+		missing = append(missing, "p")
+	}
+	if r.input.t <= 0 {
+		missing = append(missing, "t")
+	}
+	if len(missing) > 0 {
+		r.out.printf("Не заданы: %s\n", strings.Join(missing, ", "))
+		return nil
+	}
+	ctx := r.point()
+	if hasGost3Applicability(r.model) {
+		for _, warning := range applicabilityWarnings(checkApplicability(ctx)) {
+			r.out.print(warning, "\n")
+		}
+	}
+	gp, err := r.model.Properties(ctx)
+	if err != nil {
+		return err
+	}
+	r.out.printf("%s, p = %g МПа, t = %g °С, сумма долей %g %%\n", r.model.Name(), ctx.p, ctx.t-273.15, r.total())
+	for _, prop := range tableProperties {
+		if v := prop.value(gp); v != 0 {
+			r.out.printf("  %s: %s\n", prop.title, formatTableValue(v))
+		}
+	}
+	return nil
+}
+
+func (r *repl) showInput() {
+	for _, cf := range r.input.fractions {
+		r.out.printf("%s %s\n", cf.component.name, formatInputValue(cf.fraction*100))
+	}
+	r.out.printf("Сумма долей: %g %%\n", r.total())
+	if r.input.p > 0 {
+		r.out.printf("p %s МПа\n", formatInputValue(r.input.p))
+	}
+	if r.input.t > 0 {
+		r.out.printf("t %s °С\n", formatInputValue(r.input.t-273.15))
+	}
+	r.out.printf("Метод расчета: %s\n", r.model.Name())
+}
+
+func (r *repl) showDU() error {
+	if len(r.input.fractions) == 0 {
+		return errors.New("composition is not set")
+	}
+	m := newMixture(r.point())
+	r.out.writeDU(m.d, m.u)
+	return nil
+}
+
+// таблица свойств при изменении давления или температуры, остальные исходные данные - текущие
+func (r *repl) sweep(args []string) error {
+	if len(args) != 4 {
+		return errors.New("usage: sweep p|t <from> <to> <step>")
+	}
+	var bounds [3]float64
+	for i, arg := range args[1:] {
+		v, err := strconv.ParseFloat(strings.ReplaceAll(arg, ",", "."), 64)
+		if err != nil {
+			return fmt.Errorf("cannot parse value: %s", arg)
+		}
+		bounds[i] = v
+	}
+	values, err := newRange(bounds[0], bounds[1], bounds[2])
+	if err != nil {
+		return err
+	}
+	if len(r.input.fractions) == 0 {
+		return errors.New("composition is not set")
+	}
+	ctx := r.point()
+	pValues, tValues := []float64{ctx.p}, []float64{ctx.t - 273.15}
+	var header string
+	switch strings.ToLower(args[0]) {
+	case "p":
+		pValues, header = values, "p, МПа"
+		if ctx.t <= 0 {
+			return errors.New("t is not set")
+		}
+	case "t":
+		tValues, header = values, "t, °С"
+		if ctx.p <= 0 {
+			return errors.New("p is not set")
+		}
+	default:
+		return fmt.Errorf("unknown sweep parameter: %s", args[0])
+	}
+	pt, err := getPropertyTable(ctx, r.model, pValues, tValues, tableProperties)
+	if err != nil {
+		return err
+	}
+	for _, warning := range pt.warnings {
+		r.out.print(warning, "\n")
+	}
+	// значение k-го свойства в i-й точке
+	value := func(i, k int) float64 {
+		if len(pValues) > 1 {
+			return pt.values[i][0][k]
+		}
+		return pt.values[0][i][k]
+	}
+	// выводятся только свойства, рассчитываемые методом
+	var columns []int
+	for k := range pt.properties {
+		for i := range values {
+			if value(i, k) != 0 {
+				columns = append(columns, k)
+				break
+			}
+		}
+	}
+	r.out.printf("%12s", header)
+	for _, k := range columns {
+		r.out.printf("  %12s", pt.properties[k].key)
+	}
+	r.out.print("\n")
+	for i, v := range values {
+		r.out.printf("%12s", formatTableValue(v))
+		for _, k := range columns {
+			r.out.printf("  %12s", formatTableValue(value(i, k)))
+		}
+		r.out.print("\n")
+	}
+	return nil
+}
+
+// число в исходном файле без ошибок округления вида 1.7999999999999998
+func formatInputValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 10, 64)
+}
+
+// сохранение исходных данных в формате, который читает readInput
+func (r *repl) save(path string) error {
+	var sb strings.Builder
+	for _, cf := range r.input.fractions {
+		fmt.Fprintf(&sb, "%s %s\n", cf.component.name, formatInputValue(cf.fraction*100))
+	}
+	params := []struct {
+		name  string
+		value float64
+		set   bool
+	}{
+		{"p", r.input.p, r.input.p != 0},
+		{"t", r.input.t - 273.15, r.input.t != 0},
+		{"rhoc", r.input.rhoc, r.input.rhoc != 0},
+		{"rho", r.input.rho, r.input.rho != 0},
+		{"h", r.input.h, r.input.hSet},
+		{"s", r.input.s, r.input.sSet},
+		{"влагосодержание", r.input.waterContent, r.input.waterContent != 0},
+		{"точка росы", r.input.waterDewPoint - 273.15, r.input.waterDewPoint != 0},
+	}
+	for _, param := range params {
+		if param.set {
+			fmt.Fprintf(&sb, "%s %s\n", param.name, formatInputValue(param.value))
+		}
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
+func (r *repl) load(path string) error {
+	ctx, err := readInput(path)
+	if err != nil {
+		return err
+	}
+	ctx.fractions, ctx.inputFractions = ctx.inputFractions, nil
+	r.input = ctx
+	return nil
+}
+
+// выполнение одной команды. Возвращает false для команды выхода
+func (r *repl) execute(line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return true, nil
+	}
+	args := fields[1:]
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), fields[0]))
+	switch strings.ToLower(fields[0]) {
+	case "set":
+		if err := r.set(rest); err != nil {
+			return true, err
+		}
+		return true, r.showProperties()
+	case "unset":
+		if err := r.unset(rest); err != nil {
+			return true, err
+		}
+		return true, r.showProperties()
+	case "model":
+		model, err := newModel(strings.ToLower(rest), r.opts)
+		if err != nil {
+			return true, err
+		}
+		r.model = model
+		return true, r.showProperties()
+	case "sweep":
+		return true, r.sweep(args)
+	case "show":
+		switch strings.ToLower(rest) {
+		case "":
+			return true, r.showProperties()
+		case "input":
+			r.showInput()
+			return true, nil
+		case "du":
+			return true, r.showDU()
+		}
+		return true, fmt.Errorf("unknown show argument: %s", rest)
+	case "load":
+		if err := r.load(rest); err != nil {
+			return true, err
+		}
+		return true, r.showProperties()
+	case "save":
+		if rest == "" {
+			return true, errors.New("missing file path")
+		}
+		return true, r.save(rest)
+	case "help", "?":
+		r.out.printf(replHelp, strings.Join(modelOrder, ", "))
+		return true, nil
+	case "quit", "exit":
+		return false, nil
+	}
+	return true, fmt.Errorf("unknown command: %s, help - list of commands", fields[0])
+}
+
+// цикл чтения команд до конца ввода или команды выхода. Ошибки команд выводятся и не прерывают работу,
+// паника расчета превращается в ошибку команды
+func (r *repl) run(in io.Reader) error {
+	sc := bufio.NewScanner(in)
+	for {
+		r.out.print("> ")
+		if r.out.err != nil {
+			return r.out.err
+		}
+		if !sc.Scan() {
+			r.out.print("\n")
+			return sc.Err()
+		}
+		more, err := r.safeExecute(sc.Text())
+		if err != nil {
+			r.out.printf("Ошибка: %v\n", err)
+		}
+		if !more {
+			return r.out.err
+		}
+	}
+}
+
+func (r *repl) safeExecute(line string) (more bool, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			more, err = true, fmt.Errorf("calculation failed: %v", p)
+		}
+	}()
+	return r.execute(line)
+}
+
+// подкоманда repl - интерактивный расчет свойств газа
+func runREPL(args []string) {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	inputPath := fs.String("i", "", "путь к исходному файлу для начальных данных. Необязательно")
+	modelName := fs.String("model", "gost3", "метод расчета, как у основной команды")
+	fs.Parse(args)
+	opts := &modelOptions{kij: defaultKij, root: rootGibbs}
+	model, err := newModel(*modelName, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	r := newREPL(&context{}, model, opts, os.Stdout)
+	if *inputPath != "" {
+		if err := r.load(*inputPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := r.showProperties(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	r.out.print("help - список команд\n")
+	if err := r.run(os.Stdin); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
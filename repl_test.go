@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	savePath := filepath.Join(t.TempDir(), "input.txt")
+	commands := []string{
+		"set метан 90",
+		"set этан 10",
+		"set p 5",
+		"set t 0",
+		"set метан 95",
+		"set метан 90",
+		"sweep p 1 3 1",
+		"sweep t 0 20 10",
+		"show du",
+		"unknown",
+		"set ксенон 1",
+		"model pr",
+		"save " + savePath,
+		"quit",
+		"set p 10",
+	}
+	var out strings.Builder
+	r := newREPL(&context{}, gost3Model{}, &modelOptions{kij: defaultKij, root: rootGibbs}, &out)
+	if err := r.run(strings.NewReader(strings.Join(commands, "\n"))); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	for _, expected := range []string{
+		"Не заданы: p, t",
+		"gost3, p = 5 МПа, t = 0 °С, сумма долей 100 %",
+		"Ошибка: full composition required",
+		"Функции молярных долей компонентов",
+		"Ошибка: unknown command: unknown",
+		"Ошибка: unknown component or parameter: ксенон",
+		"pr, p = 5 МПа",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Output does not contain %q:\n%s", expected, text)
+		}
+	}
+	for _, header := range []string{"      p, МПа", "       t, °С"} {
+		if !strings.Contains(text, header) {
+			t.Errorf("Output does not contain sweep header %q", header)
+		}
+	}
+	if strings.Contains(text, "p = 10 МПа") {
+		t.Error("Commands after quit must not be executed")
+	}
+
+	// повторное задание компонента не меняет порядок состава, сохраненный файл читается readInput
+	data, err := os.ReadFile(savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "метан 90\nэтан 10\np 5\nt 0\n"
+	if string(data) != expected {
+		t.Errorf("Expected saved input %q, actual %q", expected, data)
+	}
+	ctx, err := readInput(savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.p != 5 || ctx.t != 273.15 || len(ctx.fractions) != 2 || ctx.fractions[0].fraction != 0.9 {
+		t.Errorf("Unexpected input read back: %+v", ctx)
+	}
+
+	r = newREPL(&context{}, gost3Model{}, nil, &out)
+	if err := r.load(savePath); err != nil {
+		t.Fatal(err)
+	}
+	// состав неполный, ошибка расчета ожидаема
+	if _, err := r.execute("set метан 91"); err == nil {
+		t.Error("Expected error for incomplete composition")
+	}
+	if r.total() < 100.99 || r.total() > 101.01 {
+		t.Errorf("Expected sum of fractions 101 %% after load and set, actual %g", r.total())
+	}
+}
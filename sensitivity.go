@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Коэффициенты чувствительности коэффициента сжимаемости и плотности по ГОСТ 30319.3 к долям компонентов,
+// давлению и температуре. Производные вычисляются аналитически через правила смешения Kx, Dn и Un
+// и неявную зависимость приведенной плотности от параметров уравнения состояния. Производные по долям -
+// частные, при неизменных долях остальных компонентов и без нормировки состава.
+
+// производная степенной функции base^exp по основанию, с нулем при нулевом показателе
+func powDerivative(base, exp float64) float64 {
+	if exp == 0 {
+		return 0
+	}
+	return exp * math.Pow(base, exp-1)
+}
+
+// производные по долям смесевого параметра вида
+// (сумма x_i*p_i^(5/2))^2 + 2*сумма_{i<j} x_i*x_j*(bin_ij^5-1)*(p_i*p_j)^(5/2), возведенного в степень 1/5,
+// value - значение параметра. Параметр bin, как в getKx, берется для пары в порядке i < j
+func getPowerMixGradient(ctx *context, value float64, param func(c *gasComponent) float64, bin func(i, j int32) float64) []float64 {
+	n := ctx.length()
+	s := sum(0, n, func(i int32) float64 { return ctx.fraction(i) * math.Pow(param(ctx.component(i)), 5.0/2) })
+	grad := make([]float64, n)
+	for k := int32(0); k < n; k++ {
+		pk := param(ctx.component(k))
+		pairs := sum(0, n, func(j int32) float64 {
+			if j == k {
+				return 0
+			}
+			return ctx.fraction(j) * (math.Pow(bin(min32(k, j), max32(k, j)), 5) - 1) *
+				math.Pow(pk*param(ctx.component(j)), 5.0/2)
+		})
+		grad[k] = (2*s*math.Pow(pk, 5.0/2) + 2*pairs) / (5 * math.Pow(value, 4))
+	}
+	return grad
+}
+
+func min32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// производные смесевого параметра размера Kx по долям компонентов
+func getKxGradient(ctx *context, kx float64) []float64 {
+	return getPowerMixGradient(ctx, kx, func(c *gasComponent) float64 { return c.k }, ctx.getKBin)
+}
+
+// производные функций Dn и Un по долям компонентов: dd[i][n] = dDn/dx_i, du[i][n] = dUn/dx_i
+func getDUGradient(ctx *context, kx float64, dKx []float64) (dd, du [][]float64) {
+	nc := ctx.length()
+	g, q, f, v := getMixtureParams(ctx)
+	e, gBin := getPairParams(ctx)
+	dV := getPowerMixGradient(ctx, v, func(c *gasComponent) float64 { return c.e }, ctx.vBin)
+	dG := make([]float64, nc)
+	for k := int32(0); k < nc; k++ {
+		dG[k] = ctx.component(k).g + sum(0, nc, func(j int32) float64 {
+			if j == k {
+				return 0
+			}
+			return ctx.fraction(j) * (ctx.gBin(k, j) - 1) * (ctx.component(k).g + ctx.component(j).g)
+		})
+	}
+	dd = make([][]float64, nc)
+	du = make([][]float64, nc)
+	for k := range dd {
+		dd[k] = make([]float64, 58)
+		du[k] = make([]float64, 58)
+	}
+	for n := 0; n < 58; n++ {
+		bBin := getBnij(ctx, gBin, n)
+		// слагаемые Bn без долей, симметричные по i и j
+		pair := func(i, j int32) float64 {
+			return bBin[i][j] * math.Pow(e[i][j], noDimensions.u[n]) *
+				math.Pow(ctx.component(i).k*ctx.component(j).k, 3.0/2)
+		}
+		b := sum(0, nc, func(i int32) float64 {
+			return sum(0, nc, func(j int32) float64 { return ctx.fraction(i) * ctx.fraction(j) * pair(i, j) })
+		})
+		// сомножители Cn и их производные по G, Q, F и V
+		fg := math.Pow(g+1-noDimensions.g[n], noDimensions.g[n])
+		fq := math.Pow(q*q+1-noDimensions.q[n], noDimensions.q[n])
+		ff := math.Pow(f+1-noDimensions.f[n], noDimensions.f[n])
+		fv := math.Pow(v, noDimensions.u[n])
+		dfg := powDerivative(g+1-noDimensions.g[n], noDimensions.g[n])
+		dfq := powDerivative(q*q+1-noDimensions.q[n], noDimensions.q[n])
+		dff := powDerivative(f+1-noDimensions.f[n], noDimensions.f[n])
+		dfv := powDerivative(v, noDimensions.u[n])
+		for k := int32(0); k < nc; k++ {
+			ck := ctx.component(k)
+			dB := 2 * sum(0, nc, func(j int32) float64 { return ctx.fraction(j) * pair(k, j) })
+			dC := dfg*dG[k]*fq*ff*fv +
+				fg*dfq*2*q*ck.q*ff*fv +
+				fg*fq*dff*2*ctx.fraction(k)*ck.f*fv +
+				fg*fq*ff*dfv*dV[k]
+			dBKx := dB*math.Pow(kx, -3) - 3*b*math.Pow(kx, -4)*dKx[k]
+			if n <= 11 {
+				dd[k][n] = dBKx
+			} else if n <= 17 {
+				dd[k][n] = dBKx - dC
+				du[k][n] = dC
+			} else {
+				du[k][n] = dC
+			}
+		}
+	}
+	return dd, du
+}
+
+// производные A0 по Dn и Un при постоянных приведенных плотности и температуре
+func getA0Coefficients(sigma, tau float64) (cd, cu []float64) {
+	cd = make([]float64, 58)
+	cu = make([]float64, 58)
+	for n := 0; n < 58; n++ {
+		common := noDimensions.a[n] * math.Pow(sigma, noDimensions.b[n]) * math.Pow(tau, -noDimensions.u[n])
+		sk := math.Pow(sigma, noDimensions.k[n])
+		cd[n] = common * noDimensions.b[n]
+		cu[n] = common * (noDimensions.b[n] - noDimensions.c[n]*noDimensions.k[n]*sk) * math.Exp(-noDimensions.c[n]*sk)
+	}
+	return
+}
+
+// коэффициенты чувствительности
+type sensitivity struct {
+	z       float64
+	density float64
+	// производные по долям компонентов в порядке состава
+	dZdx   []float64
+	dRhodx []float64
+	// производные по давлению, 1/МПа и кг/(м^3*МПа)
+	dZdp   float64
+	dRhodp float64
+	// производные по температуре, 1/К и кг/(м^3*К)
+	dZdt   float64
+	dRhodt float64
+}
+
+// расчет коэффициентов чувствительности. Приведенная плотность σ удовлетворяет уравнению
+// σ*(1 + A0(σ, τ, D, U)) = π/τ, откуда ее производные находятся дифференцированием неявной функции
+func getSensitivity(ctx *context) (*sensitivity, error) {
+	if ctx.p <= 0 || ctx.t <= 0 {
+		return nil, errors.New("pressure and temperature must be set")
+	}
+	m := newMixture(ctx)
+	pi := getPi(ctx, m.p0m)
+	tau := getTau(ctx)
+	iters, err := getSigma(ctx, m.kx, pi, tau, getInitialSigma(ctx, m.kx, m.d, m.u), m.d, m.u)
+	if err != nil {
+		return nil, err
+	}
+	sigma := iters[len(iters)-1].sigma
+	a0 := getA0(ctx, sigma, tau, m.d, m.u)
+	a1 := getA1(ctx, sigma, tau, m.d, m.u)
+	// d(σ*(1 + A0))/dσ = 1 + A1, dA0/dσ = (A1 - A0)/σ
+	dA0dSigma := (a1 - a0) / sigma
+	piTau := pi / tau
+	s := &sensitivity{z: 1 + a0, density: getP(ctx, m.kx, m.mm, sigma)}
+	// отклик на изменение параметра: da0 - производная A0 при постоянной σ, dPiTau - производная π/τ,
+	// dLnRho - производная ln(Mm/Kx^3)
+	respond := func(da0, dPiTau, dLnRho float64) (dz, dRho float64) {
+		dSigma := (dPiTau - sigma*da0) / (1 + a1)
+		dz = da0 + dA0dSigma*dSigma
+		dRho = s.density * (dLnRho + dSigma/sigma)
+		return
+	}
+	cd, cu := getA0Coefficients(sigma, tau)
+	dKx := getKxGradient(ctx, m.kx)
+	dd, du := getDUGradient(ctx, m.kx, dKx)
+	n := ctx.length()
+	s.dZdx = make([]float64, n)
+	s.dRhodx = make([]float64, n)
+	for i := int32(0); i < n; i++ {
+		da0 := sum(0, 58, func(k int32) float64 { return cd[k]*dd[i][k] + cu[k]*du[i][k] })
+		dLnKx := dKx[i] / m.kx
+		s.dZdx[i], s.dRhodx[i] = respond(da0, 3*piTau*dLnKx, ctx.component(i).m/m.mm-3*dLnKx)
+	}
+	s.dZdp, s.dRhodp = respond(0, piTau/ctx.p, 0)
+	// dA0/dτ = -сумма(un*слагаемое_n)/τ, dτ/dT = 1/Lt
+	da0dTau := -sum(0, 58, func(k int32) float64 {
+		return noDimensions.u[k] * (cd[k]*m.d[k] + cu[k]*m.u[k])
+	}) / tau
+	s.dZdt, s.dRhodt = respond(da0dTau/Lt, -piTau/ctx.t, 0)
+	return s, nil
+}
+
+// подкоманда sensitivity - коэффициенты чувствительности z и плотности к долям компонентов, давлению и температуре
+func runSensitivity(args []string) {
+	fs := flag.NewFlagSet("sensitivity", flag.ExitOnError)
+	inputPath := fs.String("i", "", "путь к файлу с составом газа, давлением и температурой")
+	outputPath := fs.String("o", "", "путь к файлу для вывода. Необязательно, по умолчанию используется стандартный поток вывода")
+	strict := fs.Bool("strict", false, "отказываться от расчета, если исходные данные вне области применения ГОСТ 30319.3")
+	fs.Parse(args)
+	if *inputPath == "" {
+		fmt.Fprintln(os.Stderr, "missing input path")
+		fs.Usage()
+		os.Exit(1)
+	}
+	ctx, err := readInput(*inputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	checks := checkApplicability(ctx)
+	if *strict {
+		if err := strictApplicability(checks); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	s, err := getSensitivity(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out, err := newOutput(*outputPath, normal)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out.writeApplicability(checks)
+	out.writeSensitivity(ctx, s)
+	if err := out.close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// аналитические производные совпадают с центральными разностями
+func TestSensitivityFiniteDifferences(t *testing.T) {
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 283.15
+	s, err := getSensitivity(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := func(change func(point *context)) *gasProperties {
+		point := *ctx
+		point.fractions = append([]componentFraction(nil), ctx.fractions...)
+		change(&point)
+		return mustProperties(&point, newMixture(&point))
+	}
+	check := func(name string, dz, dRho float64, change func(point *context, h float64), h float64) {
+		plus := props(func(point *context) { change(point, h) })
+		minus := props(func(point *context) { change(point, -h) })
+		if expected := (plus.z - minus.z) / (2 * h); math.Abs(dz-expected) > 1e-4*math.Max(1, math.Abs(expected)) {
+			t.Errorf("Wrong dz/d%s; expected = %e, actual = %e", name, expected, dz)
+		}
+		if expected := (plus.density - minus.density) / (2 * h); math.Abs(dRho-expected) > 1e-4*math.Max(1, math.Abs(expected)) {
+			t.Errorf("Wrong dρ/d%s; expected = %e, actual = %e", name, expected, dRho)
+		}
+	}
+	for i := int32(0); i < ctx.length(); i++ {
+		i := i
+		check(ctx.component(i).name, s.dZdx[i], s.dRhodx[i], func(point *context, h float64) { point.fractions[i].fraction += h }, 1e-5)
+	}
+	check("p", s.dZdp, s.dRhodp, func(point *context, h float64) { point.p += h }, 1e-4)
+	check("T", s.dZdt, s.dRhodt, func(point *context, h float64) { point.t += h }, 1e-3)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// HTTP JSON API: те же методы расчета, что у основной команды, без запуска процесса на каждый расчет.
+// Доли компонентов задаются в процентах, давление в МПа, температура в °С, как в исходном файле.
+
+const (
+	// ограничение размера тела запроса, байт
+	maxRequestBody = 1 << 20
+	// ограничение числа расчетов в пакетном запросе
+	maxBatchSize = 1000
+	// время на завершение обрабатываемых запросов при остановке сервера
+	shutdownTimeout = 10 * time.Second
+)
+
+// запрос свойств газа при давлении и температуре
+type propertiesRequest struct {
+	// доли компонентов, %, по названиям компонентов
+	Composition map[string]float64 `json:"composition"`
+	// давление, МПа, и температура, °С, обязательны: указатели отличают отсутствующее поле от нуля
+	P *float64 `json:"p"`
+	T *float64 `json:"t"`
+	// плотность при стандартных условиях, кг/м^3, для методов ГОСТ 30319.2
+	Rhoc float64 `json:"rhoc,omitempty"`
+	// метод расчета и выбор корня кубического уравнения, по умолчанию gost3 и gibbs
+	Model string `json:"model,omitempty"`
+	Root  string `json:"root,omitempty"`
+	// отказ от расчета вне области применения ГОСТ 30319.3
+	Strict bool `json:"strict,omitempty"`
+}
+
+// свойства газа, не рассчитываемые методом, не выводятся
+type propertiesResponse struct {
+	Model    string   `json:"model"`
+	P        float64  `json:"p"`
+	T        float64  `json:"t"`
+	Z        float64  `json:"z"`
+	Density  float64  `json:"density"`
+	K        float64  `json:"k,omitempty"`
+	W        float64  `json:"w,omitempty"`
+	Mu       float64  `json:"mu,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ошибка API: код для программной обработки и сообщение
+type apiError struct {
+	status  int
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newAPIError(status int, code string, err error) *apiError {
+	return &apiError{status, code, err.Error()}
+}
+
+type errorResponse struct {
+	Error *apiError `json:"error"`
+}
+
+// результат одного расчета пакетного запроса: свойства или ошибка
+type batchItem struct {
+	Result *propertiesResponse `json:"result,omitempty"`
+	Error  *apiError           `json:"error,omitempty"`
+}
+
+type componentInfo struct {
+	Name string `json:"name"`
+	// молярная масса, г/моль
+	MolarMass float64 `json:"molarMass"`
+	// критические температура, К, и давление, МПа
+	CriticalTemperature float64 `json:"criticalTemperature"`
+	CriticalPressure    float64 `json:"criticalPressure"`
+}
+
+type server struct {
+	// параметры бинарного взаимодействия кубических уравнений
+	kij map[[2]*gasComponent]float64
+}
+
+// контекст расчета из запроса. Компоненты упорядочиваются как в allComponents,
+// чтобы результат не зависел от порядка ключей
+func newRequestContext(req *propertiesRequest) (*context, error) {
+	fractions := make(map[*gasComponent]float64, len(req.Composition))
+	for name, value := range req.Composition {
+		comp, ok := componentsByName[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, &compositionError{fmt.Sprintf("unknown component: %s", name)}
+		}
+		if _, ok := fractions[comp]; ok {
+			return nil, &compositionError{fmt.Sprintf("duplicate component: %s", name)}
+		}
+		if value < 0 || math.IsNaN(value) {
+			return nil, &compositionError{fmt.Sprintf("fraction must not be negative: %s", name)}
+		}
+		fractions[comp] = value
+	}
+	ctx := &context{p: *req.P, t: *req.T + 273.15, rhoc: req.Rhoc}
+	for _, comp := range allComponents {
+		if value, ok := fractions[comp]; ok {
+			ctx.fractions = append(ctx.fractions, componentFraction{comp, value / 100})
+		}
+	}
+	ctx.initFractions()
+	return ctx, nil
+}
+
+// расчет свойств по запросу
+func (s *server) calcProperties(req *propertiesRequest) (*propertiesResponse, *apiError) {
+	if req.P == nil || req.T == nil {
+		return nil, newAPIError(http.StatusBadRequest, "bad_request", errors.New("pressure p and temperature t are required"))
+	}
+	name := req.Model
+	if name == "" {
+		name = "gost3"
+	}
+	rootName := req.Root
+	if rootName == "" {
+		rootName = "gibbs"
+	}
+	root, ok := cubicRootByName[rootName]
+	if !ok {
+		return nil, newAPIError(http.StatusBadRequest, "bad_request", fmt.Errorf("unknown cubic root selection: %s", rootName))
+	}
+	model, err := newModel(name, &modelOptions{kij: s.kij, root: root})
+	if err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "unknown_model", err)
+	}
+	if err := checkCalcLimits(model, *req.P, *req.T+273.15); err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "bad_request", err)
+	}
+	ctx, err := newRequestContext(req)
+	if err != nil {
+		return nil, newAPIError(http.StatusBadRequest, "bad_composition", err)
+	}
+	var checks []rangeCheck
+	if hasGost3Applicability(model) {
+		checks = checkApplicability(ctx)
+	}
+	if req.Strict {
+		if err := strictApplicability(checks); err != nil {
+			return nil, newAPIError(http.StatusUnprocessableEntity, "out_of_range", err)
+		}
+	}
+	gp, err := model.Properties(ctx)
+	var ce *compositionError
+	if errors.As(err, &ce) {
+		return nil, newAPIError(http.StatusBadRequest, "bad_composition", err)
+	} else if err == nil {
+		err = checkFiniteProperties(gp)
+	}
+	if err != nil {
+		return nil, newAPIError(http.StatusUnprocessableEntity, "calculation_failed", err)
+	}
+	return &propertiesResponse{
+		Model:    model.Name(),
+		P:        *req.P,
+		T:        *req.T,
+		Z:        gp.z,
+		Density:  gp.density,
+		K:        gp.k,
+		W:        gp.w,
+		Mu:       gp.mu,
+		Warnings: applicabilityWarnings(checks),
+	}, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, e *apiError) {
+	writeJSON(w, e.status, errorResponse{e})
+}
+
+// разбор тела POST-запроса, ошибки возвращаются клиенту
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeAPIError(w, newAPIError(http.StatusMethodNotAllowed, "method_not_allowed",
+			fmt.Errorf("method %s not allowed", r.Method)))
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeAPIError(w, newAPIError(http.StatusBadRequest, "bad_request", fmt.Errorf("invalid JSON: %w", err)))
+		return false
+	}
+	return true
+}
+
+func (s *server) handleProperties(w http.ResponseWriter, r *http.Request) {
+	var req propertiesRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	resp, e := s.calcProperties(&req)
+	if e != nil {
+		writeAPIError(w, e)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// пакетный расчет: ошибки отдельных расчетов возвращаются в соответствующих элементах
+func (s *server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var reqs []propertiesRequest
+	if !decodeRequest(w, r, &reqs) {
+		return
+	}
+	if len(reqs) > maxBatchSize {
+		writeAPIError(w, newAPIError(http.StatusRequestEntityTooLarge, "batch_too_large",
+			fmt.Errorf("batch size %d exceeds %d", len(reqs), maxBatchSize)))
+		return
+	}
+	items := make([]batchItem, len(reqs))
+	for i := range reqs {
+		items[i].Result, items[i].Error = s.calcProperties(&reqs[i])
+	}
+	writeJSON(w, http.StatusOK, items)
+}
+
+func (s *server) handleComponents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeAPIError(w, newAPIError(http.StatusMethodNotAllowed, "method_not_allowed",
+			fmt.Errorf("method %s not allowed", r.Method)))
+		return
+	}
+	writeJSON(w, http.StatusOK, getComponentInfo())
+}
+
+func getComponentInfo() []componentInfo {
+	components := make([]componentInfo, len(allComponents))
+	for i, c := range allComponents {
+		components[i] = componentInfo{c.name, c.m, c.tcr, c.pc}
+	}
+	return components
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/properties", s.handleProperties)
+	mux.HandleFunc("/v1/batch", s.handleBatch)
+	mux.HandleFunc("/v1/components", s.handleComponents)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, newAPIError(http.StatusNotFound, "not_found", fmt.Errorf("unknown path: %s", r.URL.Path)))
+	})
+	return mux
+}
+
+// подкоманда serve - HTTP JSON API. Сервер останавливается по SIGINT или SIGTERM,
+// дожидаясь завершения обрабатываемых запросов
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "адрес для входящих соединений")
+	kijPath := fs.String("kij", "", "путь к файлу с параметрами бинарного взаимодействия kij для моделей pr и srk")
+	fs.Parse(args)
+	kij, err := readKij(*kijPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	s := &server{kij: kij}
+	srv := &http.Server{Addr: *addr, Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errs:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	case <-stop:
+	}
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// запрос с составом N1 при 5 МПа и 10 °С без закрывающей скобки, чтобы тесты добавляли поля
+var n1Request = `{"composition": ` + compositionJSON(n1Fractions) + `, "p": 5, "t": 10`
+
+// состав в процентах по названиям компонентов, как в запросах API
+func compositionJSON(fractions []componentFraction) string {
+	composition := make(map[string]float64, len(fractions))
+	for _, cf := range fractions {
+		composition[cf.component.name] = cf.fraction * 100
+	}
+	b, err := json.Marshal(composition)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func doRequest(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	s := &server{kij: defaultKij}
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestServeProperties(t *testing.T) {
+	rec := doRequest(t, http.MethodPost, "/v1/properties", n1Request+"}")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d: %s", rec.Code, rec.Body)
+	}
+	var resp propertiesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 283.15
+	density, z := getPZ(ctx)
+	if !almostEqual(resp.Density, density, 1e-9) || !almostEqual(resp.Z, z, 1e-9) || resp.Model != "gost3" {
+		t.Errorf("Wrong properties %+v; expected density = %f, z = %f", resp, density, z)
+	}
+	// 0 °С - заданная температура, а не отсутствующее поле
+	if rec := doRequest(t, http.MethodPost, "/v1/properties", n1Request+`, "t": 0}`); rec.Code != http.StatusOK {
+		t.Errorf("Unexpected status %d for 0 °C: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{http.MethodPost, "/v1/properties", `{"composition": {"метан": 90, "неон": 10}, "p": 5, "t": 10}`, http.StatusBadRequest, "bad_composition"},
+		{http.MethodPost, "/v1/properties", `{"composition": {"метан": 50}, "p": 5, "t": 10}`, http.StatusBadRequest, "bad_composition"},
+		{http.MethodPost, "/v1/properties", n1Request + `, "model": "bwr"}`, http.StatusBadRequest, "unknown_model"},
+		{http.MethodPost, "/v1/properties", n1Request + `, "p": 40, "strict": true}`, http.StatusUnprocessableEntity, "out_of_range"},
+		{http.MethodPost, "/v1/properties", `{"composition": `, http.StatusBadRequest, "bad_request"},
+		{http.MethodPost, "/v1/properties", `{"composition": {"метан": 100}, "t": 10}`, http.StatusBadRequest, "bad_request"},
+		{http.MethodPost, "/v1/properties", `{"composition": {"метан": 100}, "p": 5}`, http.StatusBadRequest, "bad_request"},
+		{http.MethodPost, "/v1/properties", `{"composition": {"метан": 100}, "p": 5, "t": -253}`, http.StatusBadRequest, "bad_request"},
+		{http.MethodPost, "/v1/properties", `{"composition": {"метан": 100}, "p": 30, "t": -173}`, http.StatusBadRequest, "bad_request"},
+		{http.MethodGet, "/v1/properties", "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{http.MethodGet, "/v2/properties", "", http.StatusNotFound, "not_found"},
+	}
+	for _, test := range tests {
+		rec := doRequest(t, test.method, test.path, test.body)
+		var resp errorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error == nil {
+			t.Errorf("%s %s: expected JSON error, got %s", test.method, test.path, rec.Body)
+			continue
+		}
+		if rec.Code != test.status || resp.Error.Code != test.code {
+			t.Errorf("%s %s %s: expected %d %s, got %d %s", test.method, test.path, test.body,
+				test.status, test.code, rec.Code, resp.Error.Code)
+		}
+	}
+}
+
+func TestServeBatch(t *testing.T) {
+	rec := doRequest(t, http.MethodPost, "/v1/batch",
+		"["+n1Request+`, "model": "pr"}, {"composition": {"метан": 50}, "p": 5, "t": 10}]`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d: %s", rec.Code, rec.Body)
+	}
+	var items []batchItem
+	if err := json.Unmarshal(rec.Body.Bytes(), &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Result == nil || items[0].Result.Model != "pr" || items[1].Error == nil {
+		t.Errorf("Expected result and error for incomplete composition; actual = %s", rec.Body)
+	}
+}
+
+func TestServeComponents(t *testing.T) {
+	rec := doRequest(t, http.MethodGet, "/v1/components", "")
+	var components []componentInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &components); err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, c := range components {
+		if seen[c.Name] {
+			t.Errorf("Duplicate component %s", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	if len(components) != len(componentsByName) {
+		t.Errorf("Expected %d components; actual = %d", len(componentsByName), len(components))
+	}
+}
+
+func TestServeCalculationLimits(t *testing.T) {
+	// кубическое уравнение описывает и жидкость, поэтому его нижний предел температуры ниже
+	rec := doRequest(t, http.MethodPost, "/v1/properties", `{"composition": {"метан": 100}, "p": 30, "t": -173, "model": "pr"}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Unexpected status %d for liquid methane by PR: %s", rec.Code, rec.Body)
+	}
+	// расчет, давший NaN, - структурированная ошибка, а не ответ 200
+	if err := checkFiniteProperties(&gasProperties{z: 1, density: math.NaN()}); err == nil {
+		t.Error("Expected error for NaN density")
+	}
+}
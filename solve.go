@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// предельное число итераций для обратных задач
+const maxSolverIterations = 100
+
+// приведенная плотность по плотности смеси, обратная к getP
+func getSigmaFromDensity(ctx *context, kx, mm float64) float64 {
+	return ctx.rho * math.Pow(kx, 3) / mm
+}
+
+// давление по плотности и температуре, итерации не требуются
+func getPressureFromDensity(ctx *context, m *mixture, sigma float64) float64 {
+	tau := getTau(ctx)
+	pi := sigma * tau * (1 + getA0(ctx, sigma, tau, m.d, m.u))
+	return pi * m.p0m
+}
+
+// итерация расчета приведенной температуры
+type tauIteration struct {
+	// приведенная температура на данном шаге итерации
+	tau float64
+	// прибавление к приведенной температуре относительно предыдущей итерации
+	dTau float64
+	// расчетное приведенное давление
+	piCalc float64
+	// относительная невязка расчетного приведенного давления
+	residual float64
+}
+
+// расчет приведенной температуры по давлению и плотности методом Ньютона
+func getTauFromDensity(ctx *context, m *mixture, sigma float64) ([]tauIteration, error) {
+	pi := getPi(ctx, m.p0m)
+	// начальное приближение берется выше температуры идеального газа: при коэффициенте
+	// сжимаемости меньше единицы и старте снизу метод Ньютона может уйти к нефизичному корню
+	tau := 1.5 * pi / sigma
+	var iters []tauIteration
+	for len(iters) < maxSolverIterations {
+		dTau := (pi - sigma*tau*(1+getA0(ctx, sigma, tau, m.d, m.u))) / (sigma * (1 + getA2(ctx, sigma, tau, m.d, m.u)))
+		tau += dTau
+		if tau <= 0 || math.IsNaN(tau) {
+			return iters, errors.New("temperature iterations diverged")
+		}
+		piCalc := sigma * tau * (1 + getA0(ctx, sigma, tau, m.d, m.u))
+		residual := math.Abs((piCalc - pi) / pi)
+		iters = append(iters, tauIteration{tau, dTau, piCalc, residual})
+		if residual < math.Pow10(-6) {
+			return iters, nil
+		}
+	}
+	return iters, fmt.Errorf("temperature iterations did not converge in %d steps", maxSolverIterations)
+}
+
+// обратная задача: давление по плотности и температуре
+func calcRhoT(ctx *context, out *output) error {
+	if ctx.rho <= 0 {
+		return errors.New("density rho must be set and positive")
+	}
+	m := newMixture(ctx)
+	sigma := getSigmaFromDensity(ctx, m.kx, m.mm)
+	tau := getTau(ctx)
+	ctx.p = getPressureFromDensity(ctx, m, sigma)
+	z := getZ(ctx, sigma, tau, m.d, m.u)
+	if out.level >= trace {
+		out.writeFractions(ctx)
+		out.writeBinaryParams(ctx)
+	}
+	if out.level >= normal {
+		out.writeKx(m.kx)
+		out.writeP0m(m.p0m)
+		out.writeMm(m.mm)
+		out.writeDU(m.d, m.u)
+		out.writeTau(tau)
+		out.writeSigma(sigma)
+		out.writePi(getPi(ctx, m.p0m))
+	}
+	out.writePressure(ctx.p)
+	out.writeZ(z)
+	return nil
+}
+
+// обратная задача: температура по давлению и плотности
+func calcPRho(ctx *context, out *output) error {
+	if ctx.rho <= 0 {
+		return errors.New("density rho must be set and positive")
+	}
+	m := newMixture(ctx)
+	sigma := getSigmaFromDensity(ctx, m.kx, m.mm)
+	iters, err := getTauFromDensity(ctx, m, sigma)
+	if out.level >= trace {
+		out.writeFractions(ctx)
+		out.writeBinaryParams(ctx)
+	}
+	if out.level >= normal {
+		out.writeKx(m.kx)
+		out.writeP0m(m.p0m)
+		out.writeMm(m.mm)
+		out.writeDU(m.d, m.u)
+		out.writeSigma(sigma)
+		out.writePi(getPi(ctx, m.p0m))
+		out.writeTauIterations(iters)
+	}
+	if err != nil {
+		return err
+	}
+	tau := iters[len(iters)-1].tau
+	ctx.t = tau * Lt
+	out.writeTemperature(ctx.t)
+	out.writeZ(getZ(ctx, sigma, tau, m.d, m.u))
+	return nil
+}
+
+// приведенная плотность при давлении и температуре из контекста
+func getSigmaPT(ctx *context, m *mixture) (float64, error) {
+	iters, err := getSigma(ctx, m.kx, getPi(ctx, m.p0m), getTau(ctx), getInitialSigma(ctx, m.kx, m.d, m.u), m.d, m.u)
+	if err != nil {
+		return 0, err
+	}
+	return iters[len(iters)-1].sigma, nil
+}
+
+// итерация расчета температуры по давлению и энтальпии или энтропии
+type temperatureIteration struct {
+	// температура на данном шаге итерации, К
+	t float64
+	// прибавление к температуре относительно предыдущей итерации
+	dT float64
+	// расчетное значение энтальпии или энтропии
+	calc float64
+	// абсолютная невязка энтальпии или энтропии
+	residual float64
+}
+
+// расчет температуры по давлению и значению функции состояния методом Ньютона.
+// value возвращает значение функции и ее производную по температуре при постоянном давлении
+func getTemperatureFlash(ctx *context, m *mixture, target float64,
+	value func(ctx *context, sigma, tau float64) (v, dvdt float64)) ([]temperatureIteration, error) {
+	point := *ctx
+	if point.t <= 0 {
+		point.t = 300
+	}
+	var iters []temperatureIteration
+	for len(iters) < maxSolverIterations {
+		sigma, err := getSigmaPT(&point, m)
+		if err != nil {
+			return iters, err
+		}
+		v, dvdt := value(&point, sigma, getTau(&point))
+		dT := (target - v) / dvdt
+		// ограничение шага защищает от выхода за пределы области, где сходится расчет плотности
+		dT = math.Max(-50, math.Min(50, dT))
+		point.t += dT
+		if point.t <= 0 || math.IsNaN(point.t) {
+			return iters, errors.New("temperature iterations diverged")
+		}
+		iters = append(iters, temperatureIteration{point.t, dT, v, math.Abs(target - v)})
+		if math.Abs(dT) < math.Pow10(-7)*point.t {
+			return iters, nil
+		}
+	}
+	return iters, fmt.Errorf("temperature iterations did not converge in %d steps", maxSolverIterations)
+}
+
+// энтальпия и ее производная по температуре - изобарная теплоемкость
+func enthalpyFunc(m *mixture) func(ctx *context, sigma, tau float64) (float64, float64) {
+	return func(ctx *context, sigma, tau float64) (float64, float64) {
+		return getH(ctx, m.mm, sigma, tau, m.d, m.u), getCp(ctx, m.mm, sigma, tau, m.d, m.u)
+	}
+}
+
+// энтропия и ее производная по температуре cp/T
+func entropyFunc(m *mixture) func(ctx *context, sigma, tau float64) (float64, float64) {
+	return func(ctx *context, sigma, tau float64) (float64, float64) {
+		return getS(ctx, m.mm, sigma, tau, m.d, m.u), getCp(ctx, m.mm, sigma, tau, m.d, m.u) / ctx.t
+	}
+}
+
+// обратная задача: температура по давлению и энтальпии
+func calcPH(ctx *context, out *output) error {
+	if !ctx.hSet {
+		return errors.New("enthalpy h must be set")
+	}
+	m := newMixture(ctx)
+	return calcFlash(ctx, out, m, ctx.h, enthalpyFunc(m), "h расч.")
+}
+
+// обратная задача: температура по давлению и энтропии
+func calcPS(ctx *context, out *output) error {
+	if !ctx.sSet {
+		return errors.New("entropy s must be set")
+	}
+	m := newMixture(ctx)
+	return calcFlash(ctx, out, m, ctx.s, entropyFunc(m), "s расч.")
+}
+
+func calcFlash(ctx *context, out *output, m *mixture, target float64,
+	value func(ctx *context, sigma, tau float64) (float64, float64), calcName string) error {
+	iters, err := getTemperatureFlash(ctx, m, target, value)
+	if out.level >= trace {
+		out.writeFractions(ctx)
+		out.writeBinaryParams(ctx)
+	}
+	if out.level >= normal {
+		out.writeKx(m.kx)
+		out.writeP0m(m.p0m)
+		out.writeMm(m.mm)
+		out.writeDU(m.d, m.u)
+		out.writeTemperatureIterations(iters, calcName)
+	}
+	if err != nil {
+		return err
+	}
+	ctx.t = iters[len(iters)-1].t
+	sigma, err := getSigmaPT(ctx, m)
+	if err != nil {
+		return err
+	}
+	tau := getTau(ctx)
+	out.writeTemperature(ctx.t)
+	out.writeP(getP(ctx, m.kx, m.mm, sigma))
+	out.writeZ(getZ(ctx, sigma, tau, m.d, m.u))
+	if out.level >= normal {
+		out.writeEnthalpy(getH(ctx, m.mm, sigma, tau, m.d, m.u))
+		out.writeEntropy(getS(ctx, m.mm, sigma, tau, m.d, m.u))
+	}
+	return nil
+}
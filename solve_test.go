@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestInverseSolversRoundTrip(t *testing.T) {
+	ctx := n1Context()
+	m := newMixture(ctx)
+	for _, tc := range n1TestCases {
+		ctx.t = tc.t
+		ctx.p = tc.p
+		density, _ := getPZ(ctx)
+		ctx.rho = density
+
+		sigma := getSigmaFromDensity(ctx, m.kx, m.mm)
+		if p := getPressureFromDensity(ctx, m, sigma); !almostEqual(p, tc.p, tc.p*1e-5) {
+			t.Errorf("Wrong p from (rho, t) at p = %f, t = %f; actual = %f", tc.p, tc.t, p)
+		}
+
+		iters, err := getTauFromDensity(ctx, m, sigma)
+		if err != nil {
+			t.Fatalf("No convergence at p = %f, t = %f: %v", tc.p, tc.t, err)
+		}
+		if tt := iters[len(iters)-1].tau * Lt; !almostEqual(tt, tc.t, 0.01) {
+			t.Errorf("Wrong t from (p, rho) at p = %f, t = %f; actual = %f", tc.p, tc.t, tt)
+		}
+	}
+}
+
+func TestCaloricConsistency(t *testing.T) {
+	ctx := &context{fractions: []componentFraction{{&methane, 1}}, p: 0.1, t: 300}
+	ctx.initFractions()
+	m := newMixture(ctx)
+	sigma := mustSigmaPT(ctx, m)
+	// изобарная теплоемкость метана при 300 К и 0,1 МПа по справочным данным 2,2316 кДж/(кг*К)
+	if cp := getCp(ctx, m.mm, sigma, getTau(ctx), m.d, m.u); !almostEqual(cp, 2.2316, 0.01) {
+		t.Errorf("Wrong cp for methane; actual = %f, expected = %f", cp, 2.2316)
+	}
+
+	ctx = n1Context()
+	m = newMixture(ctx)
+	const dt = 0.01
+	for _, tc := range n1TestCases {
+		ctx.p = tc.p
+		at := func(tt float64) (h, s, cp float64) {
+			ctx.t = tt
+			sigma := mustSigmaPT(ctx, m)
+			tau := getTau(ctx)
+			return getH(ctx, m.mm, sigma, tau, m.d, m.u), getS(ctx, m.mm, sigma, tau, m.d, m.u), getCp(ctx, m.mm, sigma, tau, m.d, m.u)
+		}
+		hPlus, sPlus, _ := at(tc.t + dt)
+		hMinus, sMinus, _ := at(tc.t - dt)
+		_, _, cp := at(tc.t)
+		if dh := (hPlus - hMinus) / (2 * dt); !almostEqual(dh, cp, cp*1e-4) {
+			t.Errorf("dh/dT != cp at p = %f, t = %f; dh/dT = %f, cp = %f", tc.p, tc.t, dh, cp)
+		}
+		if ds := (sPlus - sMinus) / (2 * dt); !almostEqual(ds, cp/tc.t, cp/tc.t*1e-4) {
+			t.Errorf("ds/dT != cp/T at p = %f, t = %f; ds/dT = %f, cp/T = %f", tc.p, tc.t, ds, cp/tc.t)
+		}
+	}
+}
+
+func TestFlashRoundTrip(t *testing.T) {
+	ctx := n1Context()
+	m := newMixture(ctx)
+	for _, tc := range n1TestCases {
+		ctx.p, ctx.t = tc.p, tc.t
+		sigma := mustSigmaPT(ctx, m)
+		tau := getTau(ctx)
+		h := getH(ctx, m.mm, sigma, tau, m.d, m.u)
+		s := getS(ctx, m.mm, sigma, tau, m.d, m.u)
+		// расчет начинается с температуры по умолчанию, а не с искомой
+		ctx.t = 0
+
+		iters, err := getTemperatureFlash(ctx, m, h, enthalpyFunc(m))
+		if err != nil {
+			t.Fatalf("PH flash did not converge at p = %f, t = %f: %v", tc.p, tc.t, err)
+		}
+		if tt := iters[len(iters)-1].t; !almostEqual(tt, tc.t, 1e-4) {
+			t.Errorf("Wrong t from PH flash at p = %f, t = %f; actual = %f", tc.p, tc.t, tt)
+		}
+
+		iters, err = getTemperatureFlash(ctx, m, s, entropyFunc(m))
+		if err != nil {
+			t.Fatalf("PS flash did not converge at p = %f, t = %f: %v", tc.p, tc.t, err)
+		}
+		if tt := iters[len(iters)-1].t; !almostEqual(tt, tc.t, 1e-4) {
+			t.Errorf("Wrong t from PS flash at p = %f, t = %f; actual = %f", tc.p, tc.t, tt)
+		}
+	}
+}
+
+func TestFlashRequiresValue(t *testing.T) {
+	ctx := n1Context()
+	ctx.p = 0.1
+	if err := calcPH(ctx, newStreamOutput(io.Discard, normal)); err == nil {
+		t.Error("PH flash must fail without h")
+	}
+	if err := calcPS(ctx, newStreamOutput(io.Discard, normal)); err == nil {
+		t.Error("PS flash must fail without s")
+	}
+	// нулевая энтальпия допустима: это энтальпия идеального газа при 25 °С
+	if err := ctx.setInput("h", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := calcPH(ctx, newStreamOutput(io.Discard, normal)); err != nil {
+		t.Errorf("PH flash with h = 0: %v", err)
+	}
+}
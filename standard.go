@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// стандартное давление, МПа
+const standardPressure = 0.101325
+
+// способ расчета коэффициента сжимаемости при стандартных условиях
+type zcMethod int
+
+const (
+	// по уравнению состояния ГОСТ 30319.3
+	zcEOS zcMethod = iota
+	// через коэффициенты суммирования компонентов
+	zcSummation
+)
+
+var zcMethodByName = map[string]zcMethod{
+	"eos": zcEOS,
+	"sum": zcSummation,
+}
+
+// стандартные условия, к которым приводится объем газа
+type standardConditions struct {
+	// температура, К
+	t float64
+	// давление, МПа
+	p      float64
+	method zcMethod
+	// температура сгорания для расчета теплоты сгорания, °С
+	tComb float64
+}
+
+// стандартные условия по температуре в °С, допускаются 0, 15 и 20 °С,
+// температура сгорания в °С - 0, 15, 20 или 25 °С
+func newStandardConditions(tc, tComb float64, method string) (*standardConditions, error) {
+	if tc != 0 && tc != 15 && tc != 20 {
+		return nil, fmt.Errorf("standard temperature must be 0, 15 or 20 °С, got %g", tc)
+	}
+	if combustionTemperatureIndex(tComb) == -1 {
+		return nil, fmt.Errorf("combustion temperature must be 0, 15, 20 or 25 °С, got %g", tComb)
+	}
+	m, ok := zcMethodByName[method]
+	if !ok {
+		return nil, fmt.Errorf("unknown standard compressibility method: %s", method)
+	}
+	return &standardConditions{tc + 273.15, standardPressure, m, tComb}, nil
+}
+
+// коэффициент сжимаемости компонента при стандартном давлении и температуре t, К
+func (c *gasComponent) zcAt(t float64) float64 {
+	switch math.Round(t - 273.15) {
+	case 0:
+		return c.zc0
+	case 15:
+		return c.zc15
+	default:
+		return c.zc
+	}
+}
+
+// коэффициент суммирования компонента sqrt(1 - Zc). У гелия и водорода Zc > 1,
+// для них коэффициент принимается равным нулю
+func (c *gasComponent) summationFactor(t float64) float64 {
+	zc := c.zcAt(t)
+	if zc >= 1 {
+		return 0
+	}
+	return math.Sqrt(1 - zc)
+}
+
+// коэффициент сжимаемости смеси при стандартных условиях методом суммирования
+func getZcSummation(ctx *context, t float64) float64 {
+	n := ctx.length()
+	return 1 - math.Pow(sum(0, n, func(i int32) float64 {
+		return ctx.fraction(i) * ctx.component(i).summationFactor(t)
+	}), 2)
+}
+
+// свойства газа при стандартных условиях
+type standardProperties struct {
+	// коэффициент сжимаемости
+	z float64
+	// плотность, кг/м^3
+	density float64
+	// молярный объем, м^3/кмоль
+	molarVolume float64
+}
+
+func getStandardProperties(ctx *context, m *mixture, sc *standardConditions) (*standardProperties, error) {
+	var z float64
+	switch sc.method {
+	case zcSummation:
+		z = getZcSummation(ctx, sc.t)
+	default:
+		point := *ctx
+		point.p, point.t = sc.p, sc.t
+		sigma, err := getSigmaPT(&point, m)
+		if err != nil {
+			return nil, err
+		}
+		z = getZ(&point, sigma, getTau(&point), m.d, m.u)
+	}
+	molarVolume := z * R * sc.t / (sc.p * math.Pow10(3))
+	return &standardProperties{
+		z:           z,
+		density:     m.mm / molarVolume,
+		molarVolume: molarVolume,
+	}, nil
+}
+
+// коэффициент приведения объема газа от рабочих к стандартным условиям K = (p*Tc*Zc)/(pc*T*Z)
+func getVolumeCorrection(ctx *context, z float64, sc *standardConditions, sp *standardProperties) float64 {
+	return ctx.p * sc.t * sp.z / (sc.p * ctx.t * z)
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestStandardConditions(t *testing.T) {
+	if _, err := newStandardConditions(25, 25, "eos"); err == nil {
+		t.Error("Expected error for standard temperature 25 °С")
+	}
+	ctx := n1Context()
+	m := newMixture(ctx)
+	for _, tc := range []float64{0, 15, 20} {
+		eos, err := newStandardConditions(tc, 25, "eos")
+		if err != nil {
+			t.Fatal(err)
+		}
+		summation, _ := newStandardConditions(tc, 25, "sum")
+		spEOS := mustStandardProperties(ctx, m, eos)
+		spSum := mustStandardProperties(ctx, m, summation)
+		if !almostEqual(spEOS.z, spSum.z, 0.0003) {
+			t.Errorf("Zc by EOS and by summation differ at %g °С; eos = %f, sum = %f", tc, spEOS.z, spSum.z)
+		}
+		for _, gc := range n1TestCases {
+			ctx.p, ctx.t = gc.p, gc.t
+			density, z := getPZ(ctx)
+			// коэффициент приведения равен отношению плотностей при рабочих и стандартных условиях
+			if k := getVolumeCorrection(ctx, z, eos, spEOS); !almostEqual(k, density/spEOS.density, k*1e-6) {
+				t.Errorf("Wrong K at p = %f, t = %f; actual = %f, expected = %f", gc.p, gc.t, k, density/spEOS.density)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// Потоковый режим: по одному JSON-запросу в строке стандартного ввода, по одному ответу в строке
+// стандартного вывода (NDJSON). Запрос и ответ - как у /v1/properties HTTP API. Ошибки в исходных
+// данных возвращаются в строке ответа, процесс продолжает работу до конца ввода.
+
+// строка запроса: запрос свойств и необязательный идентификатор, который возвращается в ответе
+type streamRequest struct {
+	ID json.RawMessage `json:"id,omitempty"`
+	propertiesRequest
+}
+
+// строка ответа: свойства или ошибка
+type streamResponse struct {
+	ID json.RawMessage `json:"id,omitempty"`
+	batchItem
+}
+
+// разбор строки запроса: строка должна содержать ровно один JSON-объект
+func parseStreamRequest(line []byte) (*streamRequest, error) {
+	var req streamRequest
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		return &req, err
+	}
+	if dec.More() {
+		return &req, errors.New("unexpected data after JSON object")
+	}
+	return &req, nil
+}
+
+// расчет по строке запроса. Паника расчета превращается в ошибку ответа, чтобы одна строка
+// не останавливала обработку потока
+func (s *server) streamLine(line []byte) (resp streamResponse) {
+	req, err := parseStreamRequest(line)
+	resp.ID = req.ID
+	if err != nil {
+		resp.Error = newAPIError(http.StatusBadRequest, "bad_request", fmt.Errorf("invalid JSON: %w", err))
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			resp.Result = nil
+			resp.Error = newAPIError(http.StatusUnprocessableEntity, "calculation_failed", fmt.Errorf("%v", r))
+		}
+	}()
+	resp.Result, resp.Error = s.calcProperties(&req.propertiesRequest)
+	return
+}
+
+// строка ответа в JSON. Ответ, который нельзя закодировать (например, с NaN), заменяется ошибкой
+// в той же строке: процесс не должен завершаться из-за исходных данных
+func encodeStreamResponse(resp streamResponse) []byte {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		resp.batchItem = batchItem{Error: newAPIError(http.StatusUnprocessableEntity, "calculation_failed",
+			fmt.Errorf("cannot encode response: %w", err))}
+		// идентификатор - уже разобранный JSON, а ошибка состоит из строк, поэтому кодирование не отказывает
+		b, _ = json.Marshal(resp)
+	}
+	return append(b, '\n')
+}
+
+// обработка потока до конца ввода. Ошибкой завершается только чтение ввода или запись вывода
+func (s *server) processStream(in io.Reader, out io.Writer) error {
+	r := bufio.NewReader(in)
+	w := bufio.NewWriter(out)
+	for {
+		line, readErr := r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if _, err := w.Write(encodeStreamResponse(s.streamLine(line))); err != nil {
+				return err
+			}
+			// ответ отдается сразу, не дожидаясь заполнения буфера
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		} else if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// подкоманда stream - потоковый расчет NDJSON через стандартные ввод и вывод
+func runStream(args []string) {
+	fs := flag.NewFlagSet("stream", flag.ExitOnError)
+	kijPath := fs.String("kij", "", "путь к файлу с параметрами бинарного взаимодействия kij для моделей pr и srk")
+	fs.Parse(args)
+	kij, err := readKij(*kijPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	s := &server{kij: kij}
+	if err := s.processStream(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestProcessStream(t *testing.T) {
+	in := strings.Join([]string{
+		`{"id": 1, "composition": {"метан": 100}, "p": 5, "t": 10}`,
+		`not json`,
+		``,
+		`{"id": "b", "composition": {"метан": 100, "ксенон": 1}, "p": 5, "t": 10}`,
+		`{"composition": {"метан": 100}, "p": 5, "t": 10} {}`,
+		`{"id": 5, "composition": {"метан": 100}, "p": 5, "t": 10, "model": "srk"}`,
+		// физически бессмысленные условия не должны ни останавливать поток, ни завершать процесс
+		`{"id": 6, "composition": {"метан": 100}, "p": 5, "t": -253}`,
+		`{"id": 7, "composition": {"метан": 100}, "p": 30, "t": -173}`,
+		`{"id": 8, "composition": {"метан": 100}, "p": 5, "t": 10}`,
+	}, "\n")
+	var out strings.Builder
+	s := &server{kij: defaultKij}
+	if err := s.processStream(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		id   string
+		code string
+	}{{"1", ""}, {"", "bad_request"}, {`"b"`, "bad_composition"}, {"", "bad_request"}, {"5", ""},
+		{"6", "bad_request"}, {"7", "bad_request"}, {"8", ""}}
+	sc := bufio.NewScanner(strings.NewReader(out.String()))
+	i := 0
+	for ; sc.Scan(); i++ {
+		var resp streamResponse
+		if err := json.Unmarshal(sc.Bytes(), &resp); err != nil {
+			t.Fatalf("Line %d is not JSON: %s", i+1, sc.Text())
+		}
+		if i >= len(expected) {
+			continue
+		}
+		if string(resp.ID) != expected[i].id {
+			t.Errorf("Line %d: expected id %s, actual %s", i+1, expected[i].id, resp.ID)
+		}
+		if expected[i].code == "" && (resp.Result == nil || resp.Error != nil) {
+			t.Errorf("Line %d: expected result, actual %s", i+1, sc.Text())
+		}
+		if expected[i].code != "" && (resp.Error == nil || resp.Error.Code != expected[i].code) {
+			t.Errorf("Line %d: expected error %s, actual %s", i+1, expected[i].code, sc.Text())
+		}
+	}
+	if i != len(expected) {
+		t.Errorf("Expected one response per non-empty line, %d; actual = %d", len(expected), i)
+	}
+}
+
+func TestEncodeStreamResponse(t *testing.T) {
+	resp := streamResponse{ID: json.RawMessage("3")}
+	resp.Result = &propertiesResponse{Model: "gost3", Z: math.NaN()}
+	var decoded streamResponse
+	if err := json.Unmarshal(encodeStreamResponse(resp), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if string(decoded.ID) != "3" || decoded.Result != nil || decoded.Error == nil || decoded.Error.Code != "calculation_failed" {
+		t.Errorf("Response that cannot be encoded must become an error line; actual = %+v", decoded)
+	}
+}
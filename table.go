@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// свойство газа, выводимое в таблицу
+type tableProperty struct {
+	// ключ для выбора свойства и для вывода в JSON
+	key string
+	// заголовок таблицы
+	title string
+	value func(p *gasProperties) float64
+	// свойство рассчитывают только методы с hasExtendedProperties
+	extended bool
+}
+
+var tableProperties = []tableProperty{
+	{"z", "Коэффициент сжимаемости z", func(p *gasProperties) float64 { return p.z }, false},
+	{"density", "Плотность газа, кг/м^3", func(p *gasProperties) float64 { return p.density }, false},
+	{"k", "Показатель адиабаты k", func(p *gasProperties) float64 { return p.k }, true},
+	{"w", "Скорость звука, м/с", func(p *gasProperties) float64 { return p.w }, true},
+	{"mu", "Динамическая вязкость, мкПа*с", func(p *gasProperties) float64 { return p.mu }, true},
+}
+
+// сетка давлений и температур со значениями свойств в узлах
+type propertyTable struct {
+	// давления, МПа
+	p []float64
+	// температуры, °С
+	t          []float64
+	properties []tableProperty
+	// values[i][j][k] - значение k-го свойства при давлении p[i] и температуре t[j]
+	values [][][]float64
+	// предупреждения о выходе за область применения метода
+	warnings []string
+}
+
+// разбор диапазона вида "начало:конец:шаг"
+func parseRange(s string) ([]float64, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("range must be in form from:to:step: %s", s)
+	}
+	var bounds [3]float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(part), ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse range %s: %w", s, err)
+		}
+		bounds[i] = v
+	}
+	return newRange(bounds[0], bounds[1], bounds[2])
+}
+
+// значения от начала до конца включительно с заданным шагом
+func newRange(from, to, step float64) ([]float64, error) {
+	if step <= 0 || to < from {
+		return nil, fmt.Errorf("invalid range, step must be positive and end not less than start: %g:%g:%g", from, to, step)
+	}
+	// число узлов считается заранее, чтобы не накапливать ошибку округления шага
+	n := int(math.Floor((to-from)/step+1e-9)) + 1
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = from + float64(i)*step
+	}
+	return values, nil
+}
+
+// свойства таблицы для метода: пустой список - все свойства, которые метод рассчитывает. Свойство,
+// которое метод не рассчитывает, дает ошибку, а не столбец нулей
+func parseTableProperties(s string, model EquationOfState) ([]tableProperty, error) {
+	var props []tableProperty
+	if strings.TrimSpace(s) == "" {
+		for _, tp := range tableProperties {
+			if !tp.extended || hasExtendedProperties(model) {
+				props = append(props, tp)
+			}
+		}
+		return props, nil
+	}
+	for _, key := range strings.Split(s, ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		found := false
+		for _, tp := range tableProperties {
+			if tp.key == key {
+				if tp.extended && !hasExtendedProperties(model) {
+					return nil, fmt.Errorf("model %s does not calculate property %s", model.Name(), key)
+				}
+				props = append(props, tp)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown property: %s", key)
+		}
+	}
+	return props, nil
+}
+
+// проверка области применения для состава и всех давлений и температур сетки
+func getTableApplicability(ctx *context, model EquationOfState, pValues, tValues []float64) []rangeCheck {
+	if !hasGost3Applicability(model) {
+		return nil
+	}
+	checks := checkComposition(ctx)
+	for _, p := range pValues {
+		checks = append(checks, pressureCheck(p))
+	}
+	for _, t := range tValues {
+		checks = append(checks, temperatureCheck(t+273.15))
+	}
+	return checks
+}
+
+// расчет свойств во всех узлах сетки выбранным методом
+func getPropertyTable(ctx *context, model EquationOfState, pValues, tValues []float64, props []tableProperty) (*propertyTable, error) {
+	pt := &propertyTable{p: pValues, t: tValues, properties: props}
+	pt.warnings = applicabilityWarnings(getTableApplicability(ctx, model, pValues, tValues))
+	// величины, зависящие только от состава, вычисляются один раз для всей сетки
+	prepared, err := model.Prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pt.values = make([][][]float64, len(pValues))
+	point := *ctx
+	for i, p := range pValues {
+		pt.values[i] = make([][]float64, len(tValues))
+		for j, t := range tValues {
+			point.p = p
+			point.t = t + 273.15
+			gp, err := prepared(&point)
+			if err != nil {
+				return nil, fmt.Errorf("p = %g МПа, t = %g °С: %w", p, t, err)
+			}
+			pt.values[i][j] = make([]float64, len(props))
+			for k, prop := range props {
+				pt.values[i][j][k] = prop.value(gp)
+			}
+		}
+	}
+	return pt, nil
+}
+
+func formatTableValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
+
+// CSV с одинаковым числом полей во всех строках: предупреждения записываются строками комментариев
+// с '#' в начале, которые пропускает csv.Reader с Comment = '#', заголовок свойства дополняется
+// пустыми полями до ширины таблицы
+func (pt *propertyTable) writeCSV(w io.Writer) error {
+	for _, warning := range pt.warnings {
+		if _, err := fmt.Fprintf(w, "# %s\n", warning); err != nil {
+			return err
+		}
+	}
+	cw := csv.NewWriter(w)
+	for k, prop := range pt.properties {
+		if k > 0 {
+			if err := cw.Write(nil); err != nil {
+				return err
+			}
+		}
+		title := make([]string, len(pt.t)+1)
+		title[0] = prop.title
+		if err := cw.Write(title); err != nil {
+			return err
+		}
+		header := []string{"p, МПа \\ t, °С"}
+		for _, t := range pt.t {
+			header = append(header, formatTableValue(t))
+		}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for i, p := range pt.p {
+			row := []string{formatTableValue(p)}
+			for j := range pt.t {
+				row = append(row, formatTableValue(pt.values[i][j][k]))
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (pt *propertyTable) writeMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	for _, warning := range pt.warnings {
+		fmt.Fprintf(&sb, "> %s\n\n", warning)
+	}
+	for k, prop := range pt.properties {
+		if k > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "### %s\n\n", prop.title)
+		sb.WriteString("| p, МПа \\ t, °С |")
+		for _, t := range pt.t {
+			fmt.Fprintf(&sb, " %s |", formatTableValue(t))
+		}
+		sb.WriteString("\n|---|")
+		sb.WriteString(strings.Repeat("---|", len(pt.t)))
+		sb.WriteString("\n")
+		for i, p := range pt.p {
+			fmt.Fprintf(&sb, "| %s |", formatTableValue(p))
+			for j := range pt.t {
+				fmt.Fprintf(&sb, " %s |", formatTableValue(pt.values[i][j][k]))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (pt *propertyTable) writeJSON(w io.Writer) error {
+	values := make(map[string][][]float64, len(pt.properties))
+	for k, prop := range pt.properties {
+		grid := make([][]float64, len(pt.p))
+		for i := range pt.p {
+			grid[i] = make([]float64, len(pt.t))
+			for j := range pt.t {
+				grid[i][j] = pt.values[i][j][k]
+			}
+		}
+		values[prop.key] = grid
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		P          []float64              `json:"p"`
+		T          []float64              `json:"t"`
+		Properties map[string][][]float64 `json:"properties"`
+		Warnings   []string               `json:"warnings,omitempty"`
+	}{pt.p, pt.t, values, pt.warnings})
+}
+
+// подкоманда table - таблицы свойств газа на сетке давлений и температур
+func runTable(args []string) {
+	fs := flag.NewFlagSet("table", flag.ExitOnError)
+	inputPath := fs.String("i", "", "путь к файлу с составом газа, давление и температура из файла не используются")
+	outputPath := fs.String("o", "", "путь к файлу для вывода. Необязательно, по умолчанию используется стандартный поток вывода")
+	pRange := fs.String("p", "0.1:15:0.5", "диапазон давлений в МПа в виде начало:конец:шаг")
+	tRange := fs.String("t", "-23.15:76.85:10", "диапазон температур в °С в виде начало:конец:шаг")
+	format := fs.String("f", "csv", "формат вывода: csv, md или json")
+	propsList := fs.String("props", "", "свойства через запятую: z, density, k, w, mu. По умолчанию все свойства, которые рассчитывает метод")
+	modelName := fs.String("model", "gost3", "метод расчета, как у основной команды. Методы ГОСТ 30319.2 и AGA8 рассчитывают только z и density")
+	strict := fs.Bool("strict", false, "отказываться от расчета, если состав или узлы сетки вне области применения ГОСТ 30319.3")
+	fs.Parse(args)
+	if *inputPath == "" {
+		fmt.Fprintln(os.Stderr, "missing input path")
+		fs.Usage()
+		os.Exit(1)
+	}
+	pValues, err := parseRange(*pRange)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	tValues, err := parseRange(*tRange)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var write func(pt *propertyTable, w io.Writer) error
+	switch strings.ToLower(*format) {
+	case "csv":
+		write = (*propertyTable).writeCSV
+	case "md", "markdown":
+		write = (*propertyTable).writeMarkdown
+	case "json":
+		write = (*propertyTable).writeJSON
+	default:
+		fmt.Fprintln(os.Stderr, "unknown table format:", *format)
+		os.Exit(1)
+	}
+	ctx, err := readInput(*inputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	model, err := newModel(*modelName, &modelOptions{kij: defaultKij, root: rootGibbs})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	props, err := parseTableProperties(*propsList, model)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *strict {
+		if err := strictApplicability(getTableApplicability(ctx, model, pValues, tValues)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	pt, err := getPropertyTable(ctx, model, pValues, tValues, props)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out, err := newOutput(*outputPath, normal)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := write(pt, out.w); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := out.close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	values, err := parseRange("0,1:15:0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 30 || values[0] != 0.1 || !almostEqual(values[29], 14.6, 1e-12) {
+		t.Errorf("Wrong range values: %v", values)
+	}
+	for _, s := range []string{"1:2", "2:1:0.5", "1:2:0", "a:2:1"} {
+		if _, err := parseRange(s); err == nil {
+			t.Errorf("Expected error for range %s", s)
+		}
+	}
+}
+
+func TestPropertyTableMatchesSinglePoint(t *testing.T) {
+	ctx := n2Context()
+	pValues := []float64{0.1, 5, 15}
+	tValues := []float64{-23.15, 26.85, 76.85}
+	pt, err := getPropertyTable(ctx, gost3Model{}, pValues, tValues, tableProperties[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range pValues {
+		for j, tc := range tValues {
+			ctx.p, ctx.t = p, tc+273.15
+			density, z := getPZ(ctx)
+			if !almostEqual(pt.values[i][j][0], z, 1e-12) {
+				t.Errorf("Wrong z at p = %f, t = %f; table = %f, single = %f", p, tc, pt.values[i][j][0], z)
+			}
+			if !almostEqual(pt.values[i][j][1], density, 1e-9) {
+				t.Errorf("Wrong density at p = %f, t = %f; table = %f, single = %f", p, tc, pt.values[i][j][1], density)
+			}
+		}
+	}
+}
+
+// метод, считающий подготовки состава и расчеты по неподготовленному составу
+type countingModel struct {
+	EquationOfState
+	prepares   int
+	properties int
+}
+
+func (m *countingModel) Properties(ctx *context) (*gasProperties, error) {
+	m.properties++
+	return m.EquationOfState.Properties(ctx)
+}
+
+func (m *countingModel) Prepare(ctx *context) (preparedProperties, error) {
+	m.prepares++
+	return m.EquationOfState.Prepare(ctx)
+}
+
+func TestPropertyTablePreparesOnce(t *testing.T) {
+	for _, model := range []EquationOfState{gost3Model{}, aga8Model{}} {
+		m := &countingModel{EquationOfState: model}
+		pt, err := getPropertyTable(n1Context(), m, []float64{0.1, 5, 10}, []float64{0, 25, 50}, tableProperties[:2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.prepares != 1 || m.properties != 0 {
+			t.Errorf("%s: mixture must be prepared once for the whole table; prepares = %d, single point calls = %d",
+				model.Name(), m.prepares, m.properties)
+		}
+		ctx := n1Context()
+		ctx.p, ctx.t = 10, 323.15
+		gp, err := model.Properties(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pt.values[2][2][0] != gp.z || pt.values[2][2][1] != gp.density {
+			t.Errorf("%s: prepared and single point results differ", model.Name())
+		}
+	}
+}
+
+func TestTablePropertiesByModel(t *testing.T) {
+	keys := func(props []tableProperty) string {
+		var s []string
+		for _, tp := range props {
+			s = append(s, tp.key)
+		}
+		return strings.Join(s, ",")
+	}
+	for _, tc := range []struct {
+		model    EquationOfState
+		expected string
+	}{
+		{gost3Model{}, "z,density,k,w,mu"},
+		{newPengRobinson(defaultKij, rootGibbs), "z,density,k,w,mu"},
+		{aga8Model{}, "z,density"},
+		{nx19Model{}, "z,density"},
+		{gerg91Model{}, "z,density"},
+	} {
+		props, err := parseTableProperties("", tc.model)
+		if err != nil || keys(props) != tc.expected {
+			t.Errorf("Wrong default properties for %s: %s, %v", tc.model.Name(), keys(props), err)
+		}
+	}
+	if props, err := parseTableProperties("z, K", gost3Model{}); err != nil || keys(props) != "z,k" {
+		t.Errorf("Wrong properties for gost3: %s, %v", keys(props), err)
+	}
+	for _, model := range []EquationOfState{aga8Model{}, nx19Model{}, gerg91Model{}} {
+		for _, key := range []string{"k", "w", "mu"} {
+			if _, err := parseTableProperties("z,"+key, model); err == nil || !strings.Contains(err.Error(), "does not calculate") {
+				t.Errorf("Expected error for %s from %s, got %v", key, model.Name(), err)
+			}
+		}
+	}
+	if _, err := parseTableProperties("z,cp", gost3Model{}); err == nil {
+		t.Error("Expected error for unknown property")
+	}
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Оценка неопределенности плотности и коэффициента сжимаемости, вызванной неопределенностью
+// исходных данных, методом Монте-Карло. Доли компонентов, давление и температура разыгрываются
+// по нормальному закону: без заданных коэффициентов корреляции независимо, с ними - через разложение
+// Холецкого корреляционной матрицы. Разыгранные доли нормируются к исходной сумме, как при обработке
+// результатов хроматографии. Поэтому заданные неопределенности относятся к долям до нормировки: после
+// нее неопределенность доли основного компонента уменьшается, а доли приобретают отрицательную
+// корреляцию в дополнение к заданной. Неопределенность самого метода расчета сюда не входит.
+
+// вероятность охвата интервала
+const coverageProbability = 0.95
+
+// наибольшая доля испытаний, в которых расчет может завершиться ошибкой: отброшенные испытания
+// смещают оценку, поэтому при большей доле результат не выводится
+const maxFailedShare = 0.01
+
+// стандартные неопределенности исходных данных
+type inputUncertainty struct {
+	// неопределенности долей компонентов, доли единицы
+	fractions map[*gasComponent]float64
+	// неопределенности давления, МПа, и температуры, К
+	p float64
+	t float64
+	// коэффициенты корреляции пар величин по именам компонентов, p и t
+	correlations map[[2]string]float64
+}
+
+// чтение неопределенностей из файла в формате исходных данных: имя компонента и неопределенность
+// доли в процентах, p - в МПа, t - в °С. Строка с двумя именами через запятую, как в файле kij,
+// задает коэффициент корреляции этих величин
+func readUncertainty(path string) (*inputUncertainty, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	u := &inputUncertainty{fractions: make(map[*gasComponent]float64), correlations: make(map[[2]string]float64)}
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		name, value, err := parseInputLine(sc.Text())
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			continue
+		}
+		if names := strings.Split(name, ","); len(names) > 1 {
+			if err := u.setCorrelation(names, value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if value < 0 {
+			return nil, fmt.Errorf("uncertainty must not be negative: %s", name)
+		}
+		if name == "t" {
+			u.t = value
+		} else if name == "p" {
+			u.p = value
+		} else if comp, ok := componentsByName[name]; ok {
+			u.fractions[comp] = value / 100
+		} else {
+			return nil, fmt.Errorf("unknown component or parameter: %s", name)
+		}
+	}
+	return u, sc.Err()
+}
+
+func (u *inputUncertainty) setCorrelation(names []string, value float64) error {
+	if len(names) != 2 {
+		return fmt.Errorf("correlation line must contain two names separated by comma: %s", strings.Join(names, ","))
+	}
+	a, b := strings.TrimSpace(names[0]), strings.TrimSpace(names[1])
+	for _, name := range []string{a, b} {
+		if _, ok := componentsByName[name]; !ok && name != "p" && name != "t" {
+			return fmt.Errorf("unknown component or parameter: %s", name)
+		}
+	}
+	if a == b {
+		return fmt.Errorf("correlation of %s with itself", a)
+	}
+	if value < -1 || value > 1 {
+		return fmt.Errorf("correlation coefficient must be in [-1; 1]: %s, %s", a, b)
+	}
+	delete(u.correlations, [2]string{b, a})
+	u.correlations[[2]string{a, b}] = value
+	return nil
+}
+
+func (u *inputUncertainty) correlation(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if r, ok := u.correlations[[2]string{a, b}]; ok {
+		return r
+	}
+	return u.correlations[[2]string{b, a}]
+}
+
+// статистика выборки значений
+type uncertaintyStats struct {
+	mean float64
+	// стандартная неопределенность - выборочное стандартное отклонение
+	u float64
+	// границы вероятностно симметричного интервала охвата
+	low  float64
+	high float64
+}
+
+func getUncertaintyStats(values []float64) uncertaintyStats {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var s uncertaintyStats
+	for _, v := range sorted {
+		s.mean += v
+	}
+	s.mean /= float64(len(sorted))
+	for _, v := range sorted {
+		s.u += (v - s.mean) * (v - s.mean)
+	}
+	s.u = math.Sqrt(s.u / float64(len(sorted)-1))
+	s.low = percentile(sorted, (1-coverageProbability)/2)
+	s.high = percentile(sorted, (1+coverageProbability)/2)
+	return s
+}
+
+// квантиль упорядоченной выборки с линейной интерполяцией
+func percentile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// результат оценки неопределенности
+type uncertaintyResult struct {
+	// число испытаний и число испытаний, в которых расчет завершился ошибкой
+	samples int
+	failed  int
+	z       uncertaintyStats
+	density uncertaintyStats
+}
+
+// исходный состав, до корректировки в initFractions
+func sourceFractions(ctx *context) []componentFraction {
+	if ctx.inputFractions != nil {
+		return ctx.inputFractions
+	}
+	return ctx.fractions
+}
+
+// разыгрываемые величины: доли компонентов в порядке исходного состава, затем давление и температура
+func uncertaintyNames(ctx *context) []string {
+	fractions := sourceFractions(ctx)
+	names := make([]string, 0, len(fractions)+2)
+	for _, cf := range fractions {
+		names = append(names, cf.component.name)
+	}
+	return append(names, "p", "t")
+}
+
+// нижнетреугольный множитель Холецкого L корреляционной матрицы разыгрываемых величин
+func (u *inputUncertainty) cholesky(ctx *context) ([][]float64, error) {
+	names := uncertaintyNames(ctx)
+	for pair := range u.correlations {
+		for _, name := range pair {
+			found := false
+			for _, n := range names {
+				found = found || n == name
+			}
+			if !found {
+				return nil, fmt.Errorf("correlation is set for component missing from gas: %s", name)
+			}
+		}
+	}
+	l := make([][]float64, len(names))
+	for i := range l {
+		l[i] = make([]float64, i+1)
+		for j := 0; j <= i; j++ {
+			sum := u.correlation(names[i], names[j])
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+			if i != j {
+				l[i][j] = sum / l[j][j]
+			} else if sum > 1e-12 {
+				l[i][i] = math.Sqrt(sum)
+			} else {
+				return nil, errors.New("correlation matrix is not positive definite")
+			}
+		}
+	}
+	return l, nil
+}
+
+// случайный состав и состояние: величины разыгрываются по нормальному закону с корреляционной
+// матрицей L*L^T, отрицательные доли заменяются нулем, затем доли нормируются так, чтобы их сумма
+// осталась прежней
+func (u *inputUncertainty) sample(ctx *context, l [][]float64, rng *rand.Rand) *context {
+	fractions := sourceFractions(ctx)
+	normal := make([]float64, len(l))
+	for i := range normal {
+		normal[i] = rng.NormFloat64()
+	}
+	// нормированное отклонение i-й величины
+	deviation := func(i int) float64 {
+		d := 0.0
+		for j, v := range l[i] {
+			d += v * normal[j]
+		}
+		return d
+	}
+	point := *ctx
+	point.fractions = make([]componentFraction, len(fractions))
+	total, sampled := 0.0, 0.0
+	for i, cf := range fractions {
+		x := math.Max(0, cf.fraction+u.fractions[cf.component]*deviation(i))
+		point.fractions[i] = componentFraction{cf.component, x}
+		total += cf.fraction
+		sampled += x
+	}
+	for i := range point.fractions {
+		point.fractions[i].fraction *= total / sampled
+	}
+	point.initFractions()
+	point.p += u.p * deviation(len(fractions))
+	point.t += u.t * deviation(len(fractions)+1)
+	return &point
+}
+
+func getUncertainty(ctx *context, model EquationOfState, u *inputUncertainty, samples int, rng *rand.Rand) (*uncertaintyResult, error) {
+	if samples < 2 {
+		return nil, errors.New("at least two Monte Carlo samples required")
+	}
+	l, err := u.cholesky(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r := &uncertaintyResult{samples: samples}
+	z := make([]float64, 0, samples)
+	density := make([]float64, 0, samples)
+	for i := 0; i < samples; i++ {
+		gp, err := model.Properties(u.sample(ctx, l, rng))
+		if err != nil || math.IsNaN(gp.z) || math.IsNaN(gp.density) {
+			r.failed++
+			continue
+		}
+		z = append(z, gp.z)
+		density = append(density, gp.density)
+	}
+	if len(z) < 2 || float64(r.failed) > maxFailedShare*float64(samples) {
+		return nil, fmt.Errorf("calculation failed in %d of %d samples, more than %g%%", r.failed, samples, maxFailedShare*100)
+	}
+	r.z = getUncertaintyStats(z)
+	r.density = getUncertaintyStats(density)
+	return r, nil
+}
+
+// расчет неопределенности выбранным методом
+func calcUncertainty(model EquationOfState, u *inputUncertainty, samples int, seed int64) func(ctx *context, out *output) error {
+	return func(ctx *context, out *output) error {
+		for comp := range u.fractions {
+			found := false
+			for _, cf := range sourceFractions(ctx) {
+				found = found || cf.component == comp
+			}
+			if !found {
+				return fmt.Errorf("uncertainty is set for component missing from gas: %s", comp.name)
+			}
+		}
+		r, err := getUncertainty(ctx, model, u, samples, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			return err
+		}
+		out.writeUncertainty(model.Name(), r)
+		return nil
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUncertaintyZeroSpread(t *testing.T) {
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 283.15
+	r, err := getUncertainty(ctx, gost3Model{}, &inputUncertainty{}, 10, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := gost3Model{}.Properties(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.z.u > 1e-12 || r.density.u > 1e-12 || !almostEqual(r.z.mean, expected.z, 1e-12) ||
+		!almostEqual(r.density.high, r.density.low, 1e-12) {
+		t.Errorf("Expected no spread without uncertainty; z = %+v, density = %+v", r.z, r.density)
+	}
+}
+
+func TestUncertaintySample(t *testing.T) {
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 283.15
+	u := &inputUncertainty{fractions: map[*gasComponent]float64{&methane: 0.002, &nitrogen: 0.005}}
+	l, err := u.cholesky(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		point := u.sample(ctx, l, rng)
+		total := 0.0
+		for _, cf := range point.inputFractions {
+			if cf.fraction < 0 {
+				t.Fatalf("Negative fraction of %s", cf.component.name)
+			}
+			total += cf.fraction
+		}
+		if !almostEqual(total, 1, 1e-12) {
+			t.Fatalf("Sum of sampled fractions must be preserved; actual = %f", total)
+		}
+	}
+}
+
+func TestUncertaintyPressure(t *testing.T) {
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 283.15
+	u := &inputUncertainty{fractions: map[*gasComponent]float64{}, p: 0.01}
+	r, err := getUncertainty(ctx, gost3Model{}, u, 500, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// при малой неопределенности результат близок к линейной оценке через производную по давлению
+	density := func(p float64) float64 {
+		point := *ctx
+		point.p = p
+		gp, err := gost3Model{}.Properties(&point)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gp.density
+	}
+	expected := (density(ctx.p+u.p) - density(ctx.p-u.p)) / 2
+	if math.Abs(r.density.u/expected-1) > 0.1 {
+		t.Errorf("Wrong density uncertainty; expected = %f, actual = %f", expected, r.density.u)
+	}
+	if r.density.low >= r.density.mean || r.density.high <= r.density.mean {
+		t.Errorf("Coverage interval [%f; %f] must contain mean %f", r.density.low, r.density.high, r.density.mean)
+	}
+}
+
+func TestUncertaintyCorrelation(t *testing.T) {
+	ctx := n1Context()
+	ctx.p, ctx.t = 5, 283.15
+	u := &inputUncertainty{fractions: map[*gasComponent]float64{}, p: 0.01, t: 0.1,
+		correlations: map[[2]string]float64{{"t", "p"}: 0.8}}
+	l, err := u.cholesky(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	const n = 20000
+	var sp, st, spp, stt, spt float64
+	for i := 0; i < n; i++ {
+		point := u.sample(ctx, l, rng)
+		dp, dt := (point.p-ctx.p)/u.p, (point.t-ctx.t)/u.t
+		sp, st, spp, stt, spt = sp+dp, st+dt, spp+dp*dp, stt+dt*dt, spt+dp*dt
+	}
+	r := (spt/n - sp/n*st/n) / math.Sqrt((spp/n-sp/n*sp/n)*(stt/n-st/n*st/n))
+	if !almostEqual(r, 0.8, 0.02) {
+		t.Errorf("Wrong sampled correlation of p and t; actual = %f, expected = 0.8", r)
+	}
+	if !almostEqual(math.Sqrt(spp/n), 1, 0.02) || !almostEqual(math.Sqrt(stt/n), 1, 0.02) {
+		t.Errorf("Correlation must not change standard uncertainties")
+	}
+	// коэффициенты корреляции, которые не могут быть одновременно, дают ошибку
+	u.correlations[[2]string{methane.name, "p"}] = 0.9
+	u.correlations[[2]string{methane.name, "t"}] = -0.9
+	if _, err := u.cholesky(ctx); err == nil || !strings.Contains(err.Error(), "not positive definite") {
+		t.Errorf("Expected error for not positive definite correlation matrix, got %v", err)
+	}
+	// водорода в газе N1 нет
+	u.correlations = map[[2]string]float64{{hydrogen.name, "p"}: 0.5}
+	if _, err := u.cholesky(ctx); err == nil || !strings.Contains(err.Error(), "missing from gas: "+hydrogen.name) {
+		t.Errorf("Expected error for correlation of component missing from gas, got %v", err)
+	}
+}
+
+func TestUncertaintyFailedSamples(t *testing.T) {
+	// у верхней границы давления NX19 часть испытаний выходит из области применения
+	ctx := n1Context()
+	ctx.p, ctx.t = gost303192MaxP-0.05, 283.15
+	u := &inputUncertainty{fractions: map[*gasComponent]float64{}, p: 0.05}
+	if _, err := getUncertainty(ctx, nx19Model{}, u, 200, rand.New(rand.NewSource(1))); err == nil {
+		t.Error("Expected error when many samples fail")
+	}
+	ctx.p = 5
+	r, err := getUncertainty(ctx, nx19Model{}, u, 200, rand.New(rand.NewSource(1)))
+	if err != nil || r.failed != 0 {
+		t.Errorf("Unexpected failures inside NX19 range: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+)
+
+// Сборка для браузера: вместо командной строки в глобальном объекте gasComponents регистрируются функции
+//
+//	calculate(request) - расчет свойств, request и результат - JSON-строки в формате потокового режима
+//	components() - JSON-строка с перечнем компонентов, как у /v1/components
+//
+// Функции доступны сразу после go.run и не выбрасывают исключений: ошибки возвращаются в поле error.
+
+func init() {
+	platformMain = runWASM
+}
+
+func registerBridge() {
+	s := &server{kij: defaultKij}
+	bridge := js.Global().Get("Object").New()
+	bridge.Set("calculate", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		request := ""
+		if len(args) > 0 && args[0].Type() == js.TypeString {
+			request = args[0].String()
+		}
+		return marshalBridge(s.streamLine([]byte(request)))
+	}))
+	bridge.Set("components", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return marshalBridge(getComponentInfo())
+	}))
+	js.Global().Set("gasComponents", bridge)
+}
+
+func marshalBridge(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse{&apiError{Code: "internal", Message: err.Error()}})
+	}
+	return string(data)
+}
+
+// регистрация функций и ожидание вызовов из JavaScript до закрытия страницы
+func runWASM() {
+	registerBridge()
+	select {}
+}
@@ -0,0 +1,51 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"syscall/js"
+	"testing"
+)
+
+func TestWASMBridge(t *testing.T) {
+	registerBridge()
+	bridge := js.Global().Get("gasComponents")
+	var resp streamResponse
+	result := bridge.Call("calculate", `{"id": 7, "composition": {"метан": 100}, "p": 5, "t": 10}`).String()
+	if err := json.Unmarshal([]byte(result), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.ID) != "7" || resp.Error != nil || resp.Result == nil {
+		t.Fatalf("Unexpected response: %s", result)
+	}
+	ctx := &context{p: 5, t: 283.15, fractions: []componentFraction{{&methane, 1}}}
+	ctx.initFractions()
+	gp, err := gost3Model{}.Properties(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(resp.Result.Z-gp.z) > 1e-12 {
+		t.Errorf("Expected z %g, actual %g", gp.z, resp.Result.Z)
+	}
+
+	for _, arg := range []interface{}{"not json", 1} {
+		resp = streamResponse{}
+		result = bridge.Call("calculate", arg).String()
+		if err := json.Unmarshal([]byte(result), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Error == nil || resp.Error.Code != "bad_request" {
+			t.Errorf("Expected bad_request for %v, actual %s", arg, result)
+		}
+	}
+
+	var components []componentInfo
+	if err := json.Unmarshal([]byte(bridge.Call("components").String()), &components); err != nil {
+		t.Fatal(err)
+	}
+	if len(components) != len(allComponents) || components[0].Name != methane.name {
+		t.Errorf("Unexpected components: %v", components)
+	}
+}
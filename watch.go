@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Режим наблюдения (-watch): исходный файл опрашивается с заданным периодом и пересчитывается
+// после каждого изменения. Результат записывается во временный файл и переименовывается в файл вывода,
+// чтобы читатели не видели частично записанный результат. При ошибке чтения или расчета
+// остается предыдущий результат: программа хроматографа может прочитать файл во время перезаписи.
+
+// признаки изменения файла
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func getFileState(path string) (fileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{info.ModTime(), info.Size()}, nil
+}
+
+// атомарная запись: временный файл в том же каталоге, затем переименование
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// после успешного переименования временного файла уже нет, ошибка удаления не важна
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// расчет по исходному файлу в буфер, чтобы при ошибке не затронуть предыдущий результат
+func recalculate(inputPath string, level verbosity, calc func(ctx *context, out *output) error) ([]byte, error) {
+	ctx, err := readInput(inputPath)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	out := newStreamOutput(&buf, level)
+	if err := calc(ctx, out); err != nil {
+		return nil, err
+	}
+	if err := out.close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// пересчет после изменения исходного файла
+func processInput(inputPath, outputPath string, level verbosity, calc func(ctx *context, out *output) error, log io.Writer) {
+	now := time.Now().Format(time.RFC3339)
+	data, err := recalculate(inputPath, level, calc)
+	if err != nil {
+		fmt.Fprintf(log, "%s: previous results kept: %v\n", now, err)
+		return
+	}
+	if outputPath == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			fmt.Fprintln(log, err)
+		}
+		return
+	}
+	if err := writeFileAtomic(outputPath, data); err != nil {
+		fmt.Fprintf(log, "%s: previous results kept: %v\n", now, err)
+		return
+	}
+	fmt.Fprintf(log, "%s: recalculated %s\n", now, outputPath)
+}
+
+// наблюдение за исходным файлом до закрытия stop. Первый расчет выполняется сразу.
+// Без пути вывода результаты пишутся в стандартный поток вывода, ошибки и сообщения - в log
+func watchInput(inputPath, outputPath string, interval time.Duration, level verbosity,
+	calc func(ctx *context, out *output) error, log io.Writer, stop <-chan struct{}) {
+	var last fileState
+	// сообщение о недоступности файла выводится один раз, а не на каждом опросе
+	lastStatErr := ""
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		state, err := getFileState(inputPath)
+		if err != nil {
+			if err.Error() != lastStatErr {
+				lastStatErr = err.Error()
+				fmt.Fprintln(log, err)
+			}
+		} else {
+			lastStatErr = ""
+			if state != last {
+				last = state
+				processInput(inputPath, outputPath, level, calc, log)
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// журнал, безопасный для записи из горутины наблюдения
+type syncBuilder struct {
+	mu sync.Mutex
+	sb strings.Builder
+}
+
+func (b *syncBuilder) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sb.Write(p)
+}
+
+func (b *syncBuilder) count(s string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.Count(b.sb.String(), s)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchInput(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.txt")
+	outputPath := filepath.Join(dir, "output.txt")
+	// время изменения задается явно, чтобы не зависеть от точности времени файловой системы
+	modTime := time.Now().Add(-time.Hour)
+	writeInput := func(content string) {
+		if err := os.WriteFile(inputPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime = modTime.Add(time.Second)
+		if err := os.Chtimes(inputPath, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readOutput := func() string {
+		data, _ := os.ReadFile(outputPath)
+		return string(data)
+	}
+	writeInput("метан 100\np 5\nt 10\n")
+
+	var log syncBuilder
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		watchInput(inputPath, outputPath, 5*time.Millisecond, quiet, calcModel(gost3Model{}), &log, stop)
+		close(done)
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	waitFor(t, "first calculation", func() bool { return log.count("recalculated") == 1 })
+	first := readOutput()
+	if !strings.Contains(first, "Коэффициент сжимаемости z =") {
+		t.Errorf("Unexpected output: %s", first)
+	}
+
+	// файл во время перезаписи: ошибка в исходных данных не затрагивает предыдущий результат
+	writeInput("метан 1")
+	waitFor(t, "parse error", func() bool { return log.count("previous results kept") == 1 })
+	if readOutput() != first {
+		t.Error("Output changed after parse error")
+	}
+
+	writeInput("метан 100\np 7\nt 10\n")
+	waitFor(t, "second calculation", func() bool { return log.count("recalculated") == 2 })
+	if second := readOutput(); second == first {
+		t.Error("Output did not change after input change")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Temporary files left in output directory: %v", entries)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Пересчет влагосодержания газа и температуры точки росы по воде по методике ГОСТ Р 53763 (ISO 18453):
+// летучесть воды в газовой фазе по уравнению Пенга-Робинсона равна летучести конденсированной воды.
+// Конденсированной фазой считается жидкая вода, ниже 0 °С - переохлажденная.
+//
+// Отличие от стандарта: параметры бинарного взаимодействия воды с компонентами газа постоянные -
+// значения Сорейде-Уитсона для неводной (газовой) фазы из defaultKij. Температурные зависимости kij(T)
+// и функция alpha воды ISO 18453 не реализованы: коэффициентов стандарта и его контрольных примеров
+// в репозитории нет. Поэтому результат отмечается в выводе как оценочный, а не как расчет по стандарту.
+
+const (
+	// критические параметры воды для уравнения давления насыщенного пара: К, МПа
+	waterCriticalT = 647.096
+	waterCriticalP = 22.064
+	// молярный объем жидкой воды для поправки Пойнтинга, м^3/моль
+	waterLiquidVolume = 1.8e-5
+	// диапазон поиска температуры точки росы, К
+	waterDewPointMin = 173.15
+	waterDewPointMax = 473.15
+)
+
+// давление насыщенного пара воды над жидкостью по уравнению Вагнера-Прусса, t - температура в К, результат в МПа
+func waterSaturationPressure(t float64) float64 {
+	v := 1 - t/waterCriticalT
+	return waterCriticalP * math.Exp(waterCriticalT/t*(-7.85951783*v+1.84408259*math.Pow(v, 1.5)-
+		11.7866497*math.Pow(v, 3)+22.6807411*math.Pow(v, 3.5)-15.9618719*math.Pow(v, 4)+
+		1.80122502*math.Pow(v, 7.5)))
+}
+
+// летучесть конденсированной воды, МПа: давление насыщенного пара с коэффициентом летучести
+// насыщенного пара и поправкой Пойнтинга
+func (e *cubicEOS) condensedWaterFugacity(t, p float64) float64 {
+	ps := waterSaturationPressure(t)
+	pure := &context{fractions: []componentFraction{{&water, 1}}, t: t, p: ps}
+	vapor := *e
+	vapor.root = rootVapor
+	m := vapor.newMixture(pure)
+	lnPhi := vapor.getLnPhi(pure, m, vapor.solve(pure, m))[0]
+	return ps * math.Exp(lnPhi+waterLiquidVolume*(p-ps)*math.Pow10(6)/(R*t))
+}
+
+// летучесть воды в газе, МПа. Доли сухого газа нормируются и делятся с водой, мольная доля которой xw
+func (e *cubicEOS) gasWaterFugacity(ctx *context, xw, t, p float64) float64 {
+	total := sum(0, ctx.length(), ctx.fraction)
+	point := *ctx
+	point.t, point.p = t, p
+	point.fractions = make([]componentFraction, 0, ctx.length()+1)
+	for _, cf := range ctx.fractions {
+		point.fractions = append(point.fractions, componentFraction{cf.component, cf.fraction / total * (1 - xw)})
+	}
+	point.fractions = append(point.fractions, componentFraction{&water, xw})
+	vapor := *e
+	vapor.root = rootVapor
+	m := vapor.newMixture(&point)
+	lnPhi := vapor.getLnPhi(&point, m, vapor.solve(&point, m))
+	return xw * p * math.Exp(lnPhi[len(lnPhi)-1])
+}
+
+// температура точки росы по воде, К, при мольной доле воды xw и давлении p, МПа
+func (e *cubicEOS) getWaterDewPoint(ctx *context, xw, p float64) (float64, error) {
+	g := func(t float64) float64 {
+		return math.Log(e.gasWaterFugacity(ctx, xw, t, p)) - math.Log(e.condensedWaterFugacity(t, p))
+	}
+	lo, hi := waterDewPointMin, waterDewPointMax
+	if g(lo) < 0 || g(hi) > 0 {
+		return 0, fmt.Errorf("water dew point is outside %g..%g °С", lo-273.15, hi-273.15)
+	}
+	for hi-lo > 1e-6 {
+		mid := (lo + hi) / 2
+		if g(mid) > 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, nil
+}
+
+// мольная доля воды в газе, насыщенном при температуре t, К, и давлении p, МПа
+func (e *cubicEOS) getSaturatedWaterFraction(ctx *context, t, p float64) (float64, error) {
+	fl := e.condensedWaterFugacity(t, p)
+	xw := fl / p
+	for i := 0; i < maxSolverIterations; i++ {
+		// коэффициент летучести слабо зависит от доли воды, поэтому простые итерации сходятся быстро
+		next := fl * xw / e.gasWaterFugacity(ctx, xw, t, p)
+		if math.Abs(next-xw) < 1e-12*xw {
+			return next, nil
+		}
+		xw = next
+	}
+	return 0, fmt.Errorf("water fraction iterations did not converge in %d steps", maxSolverIterations)
+}
+
+// влагосодержание газа и точка росы по воде
+type waterProperties struct {
+	// влагосодержание, мг/м^3 при стандартных условиях
+	content float64
+	// температура точки росы по воде, К
+	dewPoint float64
+	// давление, при котором определена точка росы, МПа
+	p float64
+	// мольная доля воды в газе
+	fraction float64
+	// стандартные условия, к которым отнесено влагосодержание
+	std *standardConditions
+}
+
+// пересчет заданного в контексте влагосодержания или температуры точки росы при давлении из контекста.
+// Объем газа приводится к стандартным условиям контекста, по умолчанию к 20 °С
+func getWaterProperties(ctx *context) (*waterProperties, error) {
+	if ctx.waterContent > 0 && ctx.waterDewPoint > 0 {
+		return nil, errors.New("either water content or water dew point must be set, not both")
+	}
+	if ctx.p <= 0 {
+		return nil, errors.New("pressure must be set to convert water dew point")
+	}
+	std := ctx.std
+	if std == nil {
+		std = &standardConditions{t: 293.15, p: standardPressure, method: zcEOS}
+	}
+	// молярный объем сухого газа при стандартных условиях, м^3/моль
+	sp, err := getStandardProperties(ctx, newMixture(ctx), std)
+	if err != nil {
+		return nil, err
+	}
+	molarVolume := sp.molarVolume * math.Pow10(-3)
+	eos := newPengRobinson(defaultKij, rootVapor)
+	wp := &waterProperties{p: ctx.p, std: std}
+	if ctx.waterContent > 0 {
+		wp.content = ctx.waterContent
+		wp.fraction = wp.content * math.Pow10(-3) / water.m * molarVolume
+		wp.dewPoint, err = eos.getWaterDewPoint(ctx, wp.fraction, ctx.p)
+	} else {
+		wp.dewPoint = ctx.waterDewPoint
+		wp.fraction, err = eos.getSaturatedWaterFraction(ctx, wp.dewPoint, ctx.p)
+		wp.content = wp.fraction * water.m * math.Pow10(3) / molarVolume
+	}
+	if err != nil {
+		return nil, err
+	}
+	return wp, nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestWaterSaturationPressure(t *testing.T) {
+	// справочные значения IAPWS-95
+	for _, tc := range []struct{ t, p float64 }{
+		{273.16, 0.000611657},
+		{298.15, 0.0031699},
+		{373.15, 0.101418},
+	} {
+		if p := waterSaturationPressure(tc.t); !almostEqual(p, tc.p, tc.p*1e-4) {
+			t.Errorf("Wrong saturation pressure at %f K; actual = %g, expected = %g", tc.t, p, tc.p)
+		}
+	}
+}
+
+func TestWaterContentAtAtmosphericPressure(t *testing.T) {
+	// при атмосферном давлении газ почти идеальный: насыщенный при 20 °С газ содержит около 17,3 г/м^3 воды
+	ctx := n1Context()
+	ctx.p = standardPressure
+	ctx.waterDewPoint = 293.15
+	wp, err := getWaterProperties(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(wp.content, 17300, 17300*0.01) {
+		t.Errorf("Wrong water content; actual = %f", wp.content)
+	}
+}
+
+func TestWaterDewPointRoundTrip(t *testing.T) {
+	ctx := n1Context()
+	ctx.p = 5
+	for _, dewPoint := range []float64{253.15, 273.15, 293.15} {
+		ctx.waterContent, ctx.waterDewPoint = 0, dewPoint
+		wp, err := getWaterProperties(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx.waterContent, ctx.waterDewPoint = wp.content, 0
+		back, err := getWaterProperties(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !almostEqual(back.dewPoint, dewPoint, 1e-4) {
+			t.Errorf("Wrong dew point from content %f; actual = %f, expected = %f", wp.content, back.dewPoint, dewPoint)
+		}
+	}
+	ctx.waterContent, ctx.waterDewPoint = 100, 273.15
+	if _, err := getWaterProperties(ctx); err == nil {
+		t.Error("Expected error when both water content and dew point are set")
+	}
+}
@@ -0,0 +1,99 @@
+// Страница калькулятора: ввод состава и условий, перевод единиц и вызов расчета из gas-components.wasm.
+// Расчет выполняется функциями глобального объекта gasComponents, см. wasm.go.
+"use strict";
+
+const rows = [
+	["z", "Коэффициент сжимаемости", ""],
+	["density", "Плотность", "кг/м³"],
+	["k", "Показатель адиабаты", ""],
+	["w", "Скорость звука", "м/с"],
+	["mu", "Динамическая вязкость", "мкПа·с"],
+];
+
+const $ = (id) => document.getElementById(id);
+
+function fractionInputs() {
+	return Array.from($("composition").querySelectorAll("input"));
+}
+
+function showTotal() {
+	const total = fractionInputs().reduce((s, input) => s + (parseFloat(input.value) || 0), 0);
+	$("total").textContent = total.toFixed(4);
+}
+
+function buildComposition(components) {
+	for (const c of components) {
+		const label = document.createElement("label");
+		label.textContent = c.name;
+		const input = document.createElement("input");
+		input.type = "number";
+		input.step = "any";
+		input.min = "0";
+		input.name = c.name;
+		input.value = c.name === "метан" ? "100" : "";
+		input.addEventListener("input", showTotal);
+		label.appendChild(input);
+		$("composition").appendChild(label);
+	}
+	showTotal();
+}
+
+// запрос в формате HTTP API: давление в МПа, температура в °С
+function buildRequest() {
+	const composition = {};
+	for (const input of fractionInputs()) {
+		const value = parseFloat(input.value);
+		if (value > 0) {
+			composition[input.name] = value;
+		}
+	}
+	let t = parseFloat($("t").value);
+	if ($("tUnit").value === "k") {
+		t -= 273.15;
+	}
+	return {
+		composition,
+		p: parseFloat($("p").value) * parseFloat($("pUnit").value),
+		t,
+		model: $("model").value,
+	};
+}
+
+function showResponse(resp) {
+	$("error").textContent = resp.error ? resp.error.message : "";
+	$("warnings").replaceChildren(...((resp.result && resp.result.warnings) || []).map((w) => {
+		const li = document.createElement("li");
+		li.textContent = w;
+		return li;
+	}));
+	const body = $("result").tBodies[0];
+	body.replaceChildren();
+	$("result").hidden = !resp.result;
+	if (!resp.result) {
+		return;
+	}
+	for (const [key, name, unit] of rows) {
+		// свойства, не рассчитываемые методом, в ответе отсутствуют
+		if (resp.result[key] === undefined) {
+			continue;
+		}
+		const tr = body.insertRow();
+		tr.insertCell().textContent = name;
+		tr.insertCell().textContent = resp.result[key].toPrecision(6);
+		tr.insertCell().textContent = unit;
+	}
+}
+
+$("form").addEventListener("submit", (event) => {
+	event.preventDefault();
+	showResponse(JSON.parse(gasComponents.calculate(JSON.stringify(buildRequest()))));
+});
+
+const go = new Go();
+WebAssembly.instantiateStreaming(fetch("gas-components.wasm"), go.importObject).then((wasm) => {
+	go.run(wasm.instance);
+	buildComposition(JSON.parse(gasComponents.components()));
+	$("calculate").disabled = false;
+}).catch((err) => {
+	$("error").textContent = "Не удалось загрузить gas-components.wasm: " + err;
+});